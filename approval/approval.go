@@ -21,6 +21,21 @@ type Variables struct {
 	ParsedArguments any    `json:"args"`
 }
 
+// Validate precompiles the approval expression, so that it must not be compiled on each call.
+// CEL expressions will also be type-checked.
+func (a Approval) Validate() error {
+	switch strings.TrimSpace(strings.ToLower(string(a))) {
+	case "", Always, Never:
+		return nil
+	}
+
+	if isCel(a) {
+		_, err := compileCel(a)
+		return err
+	}
+	return expression.Precompile(string(a))
+}
+
 func (a Approval) NeedsApproval(ctx context.Context, jsonArgs string, td any) bool {
 	if a == "" {
 		// No approval expression is set, so we assume no approval is needed
@@ -45,7 +60,12 @@ func (a Approval) NeedsApproval(ctx context.Context, jsonArgs string, td any) bo
 		slog.Warn("error parsing arguments", "args", jsonArgs, "error", err)
 	}
 
-	b, err := expression.Run(ctx, string(a), exVars).AsBoolean()
+	var b bool
+	if isCel(a) {
+		b, err = evalCel(ctx, a, exVars)
+	} else {
+		b, err = expression.Run(ctx, string(a), exVars).AsBoolean()
+	}
 
 	if err != nil {
 		slog.Error("error running approval expression", "expression", string(a), "error", err)
@@ -63,3 +63,22 @@ func TestApproval_NeedsApproval_Run(t *testing.T) {
 	assert.NoError(t, err, "file should exist")
 	os.Remove(tmp.Name())
 }
+
+func BenchmarkApproval_NeedsApproval(b *testing.B) {
+	a := Approval(expression.VarNameContext + `.args.path.startsWith("/tmp")`)
+	args := `{"path": "/tmp/file"}`
+
+	b.Run("not precompiled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			// differs from the precompiled expression below, so it will be compiled on each call
+			Approval(string(a)+"\n").NeedsApproval(context.Background(), args, nil)
+		}
+	})
+
+	require.NoError(b, a.Validate())
+	b.Run("precompiled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			a.NeedsApproval(context.Background(), args, nil)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+// CelPrefix marks an approval expression as CEL expression (https://cel.dev) instead of JavaScript.
+const CelPrefix = "cel:"
+
+const (
+	CelVarDefinition   = "definition"
+	CelVarArguments    = "args"
+	CelVarRawArguments = "raw_args"
+)
+
+var celEnv *cel.Env
+var celEnvErr error
+var celEnvOnce = sync.Once{}
+
+var celPrograms = map[string]cel.Program{}
+var celMutex = sync.RWMutex{}
+
+func isCel(a Approval) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(a)), CelPrefix)
+}
+
+func celSource(a Approval) string {
+	return strings.TrimPrefix(strings.TrimSpace(string(a)), CelPrefix)
+}
+
+func getCelEnv() (*cel.Env, error) {
+	celEnvOnce.Do(func() {
+		celEnv, celEnvErr = cel.NewEnv(
+			cel.Variable(CelVarDefinition, cel.DynType),
+			cel.Variable(CelVarArguments, cel.DynType),
+			cel.Variable(CelVarRawArguments, cel.StringType),
+		)
+	})
+	return celEnv, celEnvErr
+}
+
+// compileCel parses and type-checks the CEL expression. The expression must evaluate to a boolean.
+func compileCel(a Approval) (cel.Program, error) {
+	celMutex.RLock()
+	prg, found := celPrograms[string(a)]
+	celMutex.RUnlock()
+	if found {
+		return prg, nil
+	}
+
+	env, err := getCelEnv()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create cel environment: %w", err)
+	}
+
+	ast, issues := env.Compile(celSource(a))
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid cel expression: %w", issues.Err())
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) && !ast.OutputType().IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("cel expression must return a boolean, but returns %s", ast.OutputType())
+	}
+
+	prg, err = env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create cel program: %w", err)
+	}
+
+	celMutex.Lock()
+	defer celMutex.Unlock()
+
+	celPrograms[string(a)] = prg
+	return prg, nil
+}
+
+func evalCel(ctx context.Context, a Approval, vars Variables) (bool, error) {
+	prg, err := compileCel(a)
+	if err != nil {
+		return false, err
+	}
+
+	// the definition will be converted into a generic structure, so that the field names are the same as in json
+	var definition any
+	if vars.ToolDefinition != nil {
+		raw, err := json.Marshal(vars.ToolDefinition)
+		if err != nil {
+			return false, fmt.Errorf("unable to serialize tool definition: %w", err)
+		}
+		if err = json.Unmarshal(raw, &definition); err != nil {
+			return false, fmt.Errorf("unable to deserialize tool definition: %w", err)
+		}
+	}
+
+	out, _, err := prg.ContextEval(ctx, map[string]any{
+		CelVarDefinition:   definition,
+		CelVarArguments:    vars.ParsedArguments,
+		CelVarRawArguments: vars.RawArguments,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to evaluate cel expression: %w", err)
+	}
+
+	b, isBool := out.Value().(bool)
+	if !isBool {
+		return false, fmt.Errorf("cel expression must return a boolean, but returns %s", out.Type())
+	}
+	return b, nil
+}
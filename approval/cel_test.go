@@ -0,0 +1,51 @@
+package approval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApproval_NeedsApproval_Cel(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		args       string
+		definition any
+		expected   bool
+	}{
+		{"args", `cel: !args.path.startsWith("/tmp")`, `{"path": "/tmp/file"}`, nil, false},
+		{"args negative", `cel: !args.path.startsWith("/tmp")`, `{"path": "/etc/passwd"}`, nil, true},
+		{"raw args", `cel:raw_args.contains("secret")`, `{"path": "/secret"}`, nil, true},
+		{"definition", `cel: definition.name == "docker"`, `{}`, &mcp.Tool{Name: "docker"}, true},
+		{"hints", `cel: !definition.annotations.readOnlyHint`, `{}`, mcp.NewTool("list", mcp.WithReadOnlyHintAnnotation(true)), false},
+		{"hints negative", `cel: !definition.annotations.readOnlyHint`, `{}`, mcp.NewTool("remove"), true},
+		{"list", `cel: args.paths.exists(p, p.startsWith("/etc"))`, `{"paths": ["/tmp", "/etc/hosts"]}`, nil, true},
+		{"missing field needs approval", `cel: args.missing == "value"`, `{}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Approval(tt.expression)
+			assert.NoError(t, a.Validate())
+			assert.Equal(t, tt.expected, a.NeedsApproval(context.Background(), tt.args, tt.definition))
+		})
+	}
+}
+
+func TestApproval_Validate_Cel(t *testing.T) {
+	assert.ErrorContains(t, Approval(`cel: args.path.startsWith(`).Validate(), "invalid cel expression")
+	assert.ErrorContains(t, Approval(`cel: raw_args + "suffix"`).Validate(), "cel expression must return a boolean, but returns string")
+	assert.ErrorContains(t, Approval(`cel: unknown == 1`).Validate(), "undeclared reference to 'unknown'")
+}
+
+func BenchmarkApproval_NeedsApproval_Cel(b *testing.B) {
+	a := Approval(`cel: args.path.startsWith("/tmp")`)
+	args := `{"path": "/tmp/file"}`
+
+	assert.NoError(b, a.Validate())
+	for i := 0; i < b.N; i++ {
+		a.NeedsApproval(context.Background(), args, nil)
+	}
+}
@@ -1,5 +1,8 @@
 package message
 
+// ScriptApproval is the name of the (pseudo) tool request which is used by the approve function of the expressions.
+const ScriptApproval = "__script_approval__"
+
 // Template definitions for each tool in different languages
 var templates = map[string]map[Language]string{
 	"executeCommand": {
@@ -115,6 +118,14 @@ Body: {{.body_preview}}{{if .body_truncated}}...{{end}}{{end}}`,
 Header:{{range $key, $value := .header}}
   {{$key}}: {{$value}}{{end}}{{end}}{{if .body}}
 Body: {{.body_preview}}{{if .body_truncated}}...{{end}}{{end}}`,
+	},
+	ScriptApproval: {
+		LanguageEnglish: `❓ {{.message}}{{if .details}}
+
+{{.details}}{{end}}`,
+		LanguageGerman: `❓ {{.message}}{{if .details}}
+
+{{.details}}{{end}}`,
 	},
 	"generic": {
 		LanguageEnglish: `Tool: {{.tool_name}}
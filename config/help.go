@@ -14,7 +14,9 @@ import (
 	toolCommand "mcp-system-control/config/model/command"
 	"mcp-system-control/expression"
 	mcpCommand "mcp-system-control/mcp/server/builtin/tools/command"
+	mcpFile "mcp-system-control/mcp/server/builtin/tools/file"
 	http2 "mcp-system-control/mcp/server/builtin/tools/http"
+	"mcp-system-control/wasm"
 
 	"github.com/fatih/color"
 	"github.com/goccy/go-yaml"
@@ -142,9 +144,11 @@ func printHelpConfig(output io.Writer) {
 }
 
 func printHelpExpression(output io.Writer) {
-	fmt.Fprintf(output, "The expression language is JavaScript. You can use the following variables and functions:\n")
+	fmt.Fprintf(output, "The expression language is JavaScript. An expression can also be a path to a JS-file.\n")
+	fmt.Fprintf(output, "Changes of such files will be reloaded automatically without restarting the server (see --expression.reloadInterval).\n")
+	fmt.Fprintf(output, "You can use the following variables and functions:\n")
 	fmt.Fprintf(output, "\nFunctions:\n")
-	fmt.Fprintf(output, "  - %s(...args): writes a message to the console.\n", expression.FuncNameLog)
+	fmt.Fprintf(output, "  - %s(...args): writes a message to the console. The message will also be sent as log notification (logger: %s) to the MCP client.\n", expression.FuncNameLog, expression.LoggerName)
 
 	js := bytes.Buffer{}
 	je := json.NewEncoder(&js)
@@ -161,6 +165,9 @@ func printHelpExpression(output io.Writer) {
 		WorkingDirectory: "/path/to/working/dir",
 	})
 	fmt.Fprintf(output, "  - %s(%s): run a command.\n", expression.FuncNameRun, strings.TrimSpace(js.String()))
+	fmt.Fprintf(output, "  - %s(cmdDescriptor): run a command and return {exitCode, stdout, stderr, durationMs, truncated}.\n", expression.FuncNameExec)
+	fmt.Fprintf(output, "      In addition to the fields of %s, the descriptor accepts \"stdin\" (string) and \"timeoutMs\" (number).\n", expression.FuncNameRun)
+	fmt.Fprintf(output, "      A non-zero exit code is not an error. Only a failing start or a timeout will throw an error.\n")
 
 	js = bytes.Buffer{}
 	je = json.NewEncoder(&js)
@@ -174,6 +181,79 @@ func printHelpExpression(output io.Writer) {
 		StringBody: `{"msg": "hello world"}`,
 	})
 	fmt.Fprintf(output, "  - %s(%s): do a http call.\n", expression.FuncNameFetch, strings.TrimSpace(js.String()))
+	fmt.Fprintf(output, "      Further (optional) fields of the descriptor:\n")
+	fmt.Fprintf(output, "        query:            map of query parameters (a value can be a list)\n")
+	fmt.Fprintf(output, "        bodyBase64:       binary body as base64 (instead of body)\n")
+	fmt.Fprintf(output, "        form:             map of form fields, sent as application/x-www-form-urlencoded (instead of body)\n")
+	fmt.Fprintf(output, "        multipart:        list of {name, value, valueBase64, fileName, contentType} (instead of body)\n")
+	fmt.Fprintf(output, "        timeoutMs:        timeout of the call in milliseconds\n")
+	fmt.Fprintf(output, "        redirect:         {disable: true} or {max: 3}\n")
+	fmt.Fprintf(output, "        tls:              {ca, clientCert, clientKey, insecureSkipVerify}; certificates/keys as PEM content or file path\n")
+	fmt.Fprintf(output, "        maxResponseBytes: maximum number of body bytes to read; the result will be marked as truncated\n")
+	fmt.Fprintf(output, "      Non-text responses are returned as \"bodyBase64\" instead of \"body\". Cookies are kept per MCP session.\n")
+	fmt.Fprintf(output, "  - %s(name, args): call another (builtin or custom) tool of this server. The call is subject to the tool's approval. Nested calls are limited to a depth of %d.\n", expression.FuncNameCallTool, expression.MaxCallDepth)
+	fmt.Fprintf(output, "  - %s(message, details): asks the user for approval (with the configured requester) and returns the decision (true/false).\n", expression.FuncNameApprove)
+	fmt.Fprintf(output, "      The details are optional and can be a string or an object.\n")
+	fmt.Fprintf(output, "  - %s({messages, maxTokens, systemPrompt, temperature}): asks the model of the MCP client (sampling) and returns the completion text.\n", expression.FuncNameSample)
+	fmt.Fprintf(output, "      A message can be a string (user message) or an object like {role: \"assistant\", content: \"...\"}. The client must support sampling.\n")
+	fmt.Fprintf(output, "  - %s.log|info|debug|warn|error|trace(...args): writes a message to the console (same as %s).\n", expression.ObjNameConsole, expression.FuncNameLog)
+	fmt.Fprintf(output, "  - %s(name): returns the value of the environment variable (or undefined if not set).\n", expression.FuncNameEnv)
+	fmt.Fprintf(output, "  - %s(ms): waits the given milliseconds.\n", expression.FuncNameSleep)
+	fmt.Fprintf(output, "  - %s.encode(str), %s.decode(str): base64 en-/decoding.\n", expression.ObjNameBase64, expression.ObjNameBase64)
+	fmt.Fprintf(output, "  - %s.encode(str), %s.decode(str): hex en-/decoding.\n", expression.ObjNameHex, expression.ObjNameHex)
+	fmt.Fprintf(output, "  - %s(str), %s(str): returns the hex encoded hash of the given string.\n", expression.FuncNameSha256, expression.FuncNameMd5)
+	fmt.Fprintf(output, "  - %s.join(...elements), %s.dirname(path): path manipulation.\n", expression.ObjNamePath, expression.ObjNamePath)
+	fmt.Fprintf(output, "  - %s.resolve(...elements): returns the absolute path. A leading '~' will be replaced with the user's home directory.\n", expression.ObjNamePath)
+	fmt.Fprintf(output, "  - %s(): returns a random uuid (v4).\n", expression.FuncNameUUID)
+	fmt.Fprintf(output, "  - %s(...args): quotes the arguments so that they can be used safely as command line (%s({\"command\": ...})).\n", expression.FuncNameShellQuote, expression.FuncNameRun)
+	fmt.Fprintf(output, "  - %s(str, vars): replaces placeholders like {{name}} or {{nested.name}} with the corresponding values of vars.\n", expression.FuncNameTemplate)
+
+	fmt.Fprintf(output, "\nGlobal variables and init scripts can be shared between all expressions (approval and command expressions):\n")
+	fmt.Fprintf(output, `
+  expression:
+    globals:
+      allowedPaths: ["/tmp", "/var/tmp"]
+    globalFiles:
+      settings: /path/to/settings.yaml          # JSON or YAML file
+    init:
+      - /path/to/helpers.js                     # script file or inline script
+      - function isAllowed(p) { return allowedPaths.some(a => p.startsWith(a)) }
+`)
+	fmt.Fprintf(output, "The global variables are read-only: each expression gets its own frozen copy of the values.\n")
+	fmt.Fprintf(output, "The builtin objects (including their prototypes) and the global objects and functions of the init scripts are frozen,\n")
+	fmt.Fprintf(output, "so that an expression can not change them for the following evaluations.\n")
+
+	fmt.Fprintf(output, "\nExpressions can be tested offline (without starting the server):\n")
+	fmt.Fprintf(output, "  %s --test-expression='%s.args.path.startsWith(\"/tmp\")' --test-expression-ctx='{\"args\": {\"path\": \"/tmp/file\"}}'\n", os.Args[0], expression.VarNameContext)
+	fmt.Fprintf(output, "Without a fixture file, the calls of %s, %s, %s, %s and %s will fail. They can be executed for real with --test-expression-live.\n", expression.FuncNameRun, expression.FuncNameExec, expression.FuncNameFetch, expression.FuncNameApprove, expression.FuncNameSample)
+	fmt.Fprintf(output, "\nWith a fixture file, the expression will be evaluated for each test case. The responses of %s, %s, %s, %s and %s can be mocked:\n", expression.FuncNameRun, expression.FuncNameExec, expression.FuncNameFetch, expression.FuncNameApprove, expression.FuncNameSample)
+	fmt.Fprintf(output, `
+  cases:
+    - name: "reads tmp file"
+      ctx: { "args": { "path": "/tmp/file" } }  # optional: overrides --test-expression-ctx
+      run:
+        - name: "ls"                            # optional: only used for commands with this name
+          command: ""                           # optional: only used for this command line
+          output: "file1\nfile2"
+          error: ""                             # if set, the call fails with this error
+      exec:
+        - name: "ls"                            # optional: only used for commands with this name
+          command: ""                           # optional: only used for this command line
+          result: { "exitCode": 1, "stdout": "", "stderr": "no such file" }
+          error: ""                             # if set, the call fails with this error
+      fetch:
+        - method: "GET"                         # optional: only used for calls with this method
+          url: "https://example.com"            # optional: only used for calls with this url
+          response: { "status_code": 200, "body": "OK" }
+          error: ""                             # if set, the call fails with this error
+      approve:
+        - message: "Delete files?"              # optional: only used for approvals with this message
+          approved: true
+          error: ""                             # if set, the approval fails with this error
+      sample:                                   # the mocks will be used in order
+        - text: "summary"
+          error: ""                             # if set, the sampling fails with this error
+`)
 }
 
 func printHelpTool(output io.Writer) {
@@ -205,6 +285,13 @@ func printHelpTool(output io.Writer) {
 			},
 			WorkingDir: "/tmp",
 			Approval:   "true",
+			Annotations: mcp.ToolAnnotation{
+				Title:           "Touch file",
+				ReadOnlyHint:    mcp.ToBoolPtr(false),
+				DestructiveHint: mcp.ToBoolPtr(false),
+				IdempotentHint:  mcp.ToBoolPtr(true),
+				OpenWorldHint:   mcp.ToBoolPtr(false),
+			},
 		},
 		{
 			Name:        "echo",
@@ -224,6 +311,10 @@ func printHelpTool(output io.Writer) {
 			},
 			Command:  "/usr/bin/echo",
 			Approval: "false",
+			Annotations: mcp.ToolAnnotation{
+				Title:        "Echo",
+				ReadOnlyHint: mcp.ToBoolPtr(true),
+			},
 		},
 	}
 
@@ -241,7 +332,10 @@ func printHelpTool(output io.Writer) {
 	ye := yaml.NewEncoder(output, yaml.Indent(2))
 	ye.Encode(model.Config{Custom: fdm})
 
-	fmt.Fprintf(output, "\nThe approval is always an js-expression. It will be evaluated each time the MCP-Server calls the function.\n")
+	fmt.Fprintf(output, "\nThe parameters must be a valid JSON schema. The arguments of each call will be validated against it (types, required,\n")
+	fmt.Fprintf(output, "enum, pattern, minimum/maximum, ...) before the approval is checked. Violations are returned to the LLM as tool error.\n")
+
+	fmt.Fprintf(output, "\nThe approval is a js-expression by default. It will be evaluated each time the MCP-Server calls the function.\n")
 	fmt.Fprintf(output, "If the expression returns true, the user must give the approval before the function will be executed.\n")
 	fmt.Fprintf(output, "If the expression returns false, the user will NOT be asked for his approval.\n")
 	fmt.Fprintf(output, "You can use the same variables and functions which are available in all other expressions (see --help-expression):\n")
@@ -260,9 +354,26 @@ func printHelpTool(output io.Writer) {
 		},
 	})
 
+	fmt.Fprintf(output, "\nAlternatively, the approval can be a CEL expression (https://cel.dev) with the prefix %q.\n", approval.CelPrefix)
+	fmt.Fprintf(output, "CEL expressions are free of side effects, fast and will be type-checked at startup. They must return a boolean.\n")
+	fmt.Fprintf(output, "The variables %s, %s and %s are available directly:\n", approval.CelVarDefinition, approval.CelVarArguments, approval.CelVarRawArguments)
+	fmt.Fprintf(output, "  %s !args.path.startsWith('/tmp/') && definition.name != 'readOnlyTool'\n", approval.CelPrefix)
+
+	fmt.Fprintf(output, "\nThe annotations are hints about the behavior of the tool (title, readOnlyHint, destructiveHint, idempotentHint and\n")
+	fmt.Fprintf(output, "openWorldHint). Clients use them to decide which tools can be run without asking the user. All builtin tools are\n")
+	fmt.Fprintf(output, "annotated. A tool without hints is treated as destructive. The hints are also available in the approval expressions:\n")
+	fmt.Fprintf(output, "  %s !definition.annotations.readOnlyHint\n", approval.CelPrefix)
+	fmt.Fprintf(output, "The approval of all read-only tools (see --approval.readOnlyTools) and all destructive tools (see --approval.destructiveTools)\n")
+	fmt.Fprintf(output, "can be overridden at once. In read-only mode (see --read-only), only the read-only tools will be provided.\n")
+
 	fmt.Fprintf(output, "\nThe LLM will respond the arguments as JSON. You can use the following placeholders in the command:\n")
 	fmt.Fprintf(output, "  - $@: all arguments (1:1 the JSON from the LLM)\n")
 	fmt.Fprintf(output, "  - $<varName>: the value of <varName> in the LLM's JSON\n")
+	fmt.Fprintf(output, "  - ${<a>.<b>}: nested access (objects and arrays) to the LLM's JSON\n")
+	fmt.Fprintf(output, "  - ${<varName>:-<default>}: the default value if <varName> is missing or empty\n")
+	fmt.Fprintf(output, "  - ${<varName>:?<message>}: the call fails if <varName> is missing or empty\n")
+	fmt.Fprintf(output, "  - \"${<varName>[@]}\": each element of the array <varName> as own argument\n")
+	fmt.Fprintf(output, "  - ${<varName>:+<flag>}: the <flag> only if <varName> is given and not false\n")
 	fmt.Fprintf(output, "\nExamples:\n")
 
 	table := tablewriter.NewWriter(output)
@@ -274,10 +385,108 @@ func printHelpTool(output io.Writer) {
 	table.Append([]string{`/usr/bin/echo $message`, `{"message": "hello world"}`, `/usr/bin/echo hello world`})
 	table.Append([]string{`/usr/bin/echo "$message"`, `{"message": "hello world"}`, `/usr/bin/echo "hello world"`})
 	table.Append([]string{`/usr/bin/echo "$message"`, `{}`, `/usr/bin/echo ""`})
+	table.Append([]string{`/usr/bin/ls "${config.dir}"`, `{"config": {"dir": "/tmp"}}`, `/usr/bin/ls "/tmp"`})
+	table.Append([]string{`/usr/bin/run --mode "${mode:-fast}"`, `{}`, `/usr/bin/run --mode "fast"`})
+	table.Append([]string{`/usr/bin/rm "${path:?}"`, `{}`, `error: missing required argument 'path'`})
+	table.Append([]string{`/usr/bin/rm "${files[@]}"`, `{"files": ["a b", "c"]}`, `/usr/bin/rm "a b" "c"`})
+	table.Append([]string{`/usr/bin/rm ${force:+--force} "$path"`, `{"force": true, "path": "/tmp/x"}`, `/usr/bin/rm --force "/tmp/x"`})
 
 	table.Render()
 
-	fmt.Fprintf(output, "\nYou can also use these placeholder in (additional) environment and working directory variables.\n")
+	fmt.Fprintf(output, "\nYou can also use these placeholder in (additional) environment, working directory and stdin variables.\n")
+	fmt.Fprintf(output, "Large contents should be passed via stdin instead of the command line (arguments are visible for other processes, e.g. in ps):\n")
+	fmt.Fprintf(output, `
+  custom:
+    query:
+      description: "Executes a SQL query."
+      command: "psql --no-psqlrc --csv"
+      stdin: "${sql:?}"
+`)
+
+	fmt.Fprintf(output, "\nThe output of a command can be post-processed (see --output.*). The steps will be applied in the following order:\n")
+	fmt.Fprintf(output, "stream selection, regex extraction, jq filter, structured content and at last the truncation by lines and bytes.\n")
+	fmt.Fprintf(output, `
+  custom:
+    pods:
+      description: "Lists the names of all running pods."
+      command: "kubectl get pods -o json"
+      output:
+        stream: stdout
+        jq: '{pods: [.items[] | select(.status.phase == "Running") | .metadata.name]}'
+        structured: true
+`)
+
+	fmt.Fprintf(output, "\nBy default, the exit code of a command is ignored. With failOnExitCode, an unsuccessful exit code (see successExitCodes)\n")
+	fmt.Fprintf(output, "will be reported as error result including the exit code and the end of stderr. If a command exceeds its timeout, it\n")
+	fmt.Fprintf(output, "will be terminated (SIGTERM) and killed after the grace period. A timeout will always be reported as error result.\n")
+	fmt.Fprintf(output, "The same settings are available for the builtin command execution (see --builtin.command-execution.*).\n")
+	fmt.Fprintf(output, `
+  custom:
+    lint:
+      description: "Runs the linter. Reports an error if there are any findings."
+      command: "golangci-lint run ./..."
+      timeout: 5m
+      gracePeriod: 10s
+      successExitCodes: [0]
+      failOnExitCode: true
+`)
+
+	fmt.Fprintf(output, "\nInstead of a single command, multiple steps can be piped together. The stdout of each step is the stdin of the next\n")
+	fmt.Fprintf(output, "step. The stdin of the tool is passed to the first step. The output of the last step is the result of the tool.\n")
+	fmt.Fprintf(output, "If a step exits with an unsuccessful exit code (see successExitCodes), the tool call will fail with an error result\n")
+	fmt.Fprintf(output, "(even if the following steps succeed) unless the step has continueOnError. Steps which are terminated because their\n")
+	fmt.Fprintf(output, "successor has stopped reading (SIGPIPE) are not treated as failed.\n")
+	fmt.Fprintf(output, `
+  custom:
+    searchCode:
+      description: "Searches for a pattern in all go files of a directory."
+      parameters:
+        type: object
+        properties:
+          dir:
+            type: string
+          pattern:
+            type: string
+        required: [dir, pattern]
+      steps:
+        - command: 'find "$dir" -name "*.go"'
+        - command: 'xargs grep -n "$pattern"'
+          continueOnError: true # grep exits with 1 if nothing was found
+        - command: "head -n 50"
+`)
+
+	fmt.Fprintf(output, "\nInstead of a command, a tool can also execute a http call (http). The method, url, query, header and body use the same\n")
+	fmt.Fprintf(output, "placeholders as the command (without field splitting). Responses with an unexpected status code (default: 2xx) will be\n")
+	fmt.Fprintf(output, "reported as error result. The extraction path (jq-style) and the output post-processing will be applied to the response.\n")
+	fmt.Fprintf(output, "The values are escaped: inside the url they are url-encoded, inside a JSON body (default content type) string values\n")
+	fmt.Fprintf(output, "are JSON-escaped and all other values are inserted as JSON, inside a form body they are url-encoded.\n")
+	fmt.Fprintf(output, "Approval and parameter validation work the same way as for commands. Example:\n")
+	fmt.Fprintf(output, `
+  custom:
+    create-issue:
+      description: "Creates an issue and returns its url."
+      parameters:
+        type: object
+        properties:
+          title:
+            type: string
+          labels:
+            type: array
+            items:
+              type: string
+        required: [title]
+      http:
+        method: POST
+        url: "https://api.github.com/repos/owner/repo/issues"
+        header:
+          Accept: "application/vnd.github+json"
+          Content-Type: "application/json"
+        body: '{"title": "${title}", "labels": ${labels:-[]}}'
+        expectedStatus: [201]
+        extract: ".html_url"
+      timeout: 10s
+      approval: true
+`)
 
 	fmt.Fprintf(output, "\nIt is also possible to define a JavaScript expression (file).\n")
 	fmt.Fprintf(output, "You can use the same variables and functions which are available in all other expressions (see --help-expression):\n")
@@ -327,4 +536,93 @@ func printHelpTool(output io.Writer) {
   result
 `)
 
+	fmt.Fprintf(output, "\n\n  // return a structured and multi-part result (a plain string will be treated as text)\n")
+	fmt.Fprintf(output, `
+  ({
+     "content": [
+        "a plain text part",
+        { "type": "image", "data": "<base64>", "mimeType": "image/png" },
+        { "type": "resource_link", "uri": "file:///tmp/report.pdf", "name": "report" },
+        { "type": "resource", "resource": { "uri": "file:///tmp/blob", "mimeType": "application/octet-stream", "blob": "<base64>" } }
+     ],
+     "structuredContent": { "count": 2 },
+     "isError": false
+  })
+`)
+
+	fmt.Fprintf(output, "\nCustom tools can also be defined in a drop-in directory (see --custom-dir.path). Each file (*.yaml, *.yml or *.json)\n")
+	fmt.Fprintf(output, "contains one tool definition in the same shape as above. The file name (without extension) is the name of the tool.\n")
+	fmt.Fprintf(output, "Changes of the directory will be applied to the running server (see --custom-dir.reloadInterval): tools will be added,\n")
+	fmt.Fprintf(output, "updated or removed and the clients will be notified (notifications/tools/list_changed). If a changed file is invalid,\n")
+	fmt.Fprintf(output, "the error will be logged and the previous version of the tool will be kept. Tools which are defined elsewhere can not be\n")
+	fmt.Fprintf(output, "overwritten by a file. Example (/etc/mcp-system-control/tools.d/disk-usage.yaml):\n")
+	fmt.Fprintf(output, `
+  description: "Shows the disk usage of all mounted filesystems."
+  command: "df -h"
+  approval: false
+`)
+
+	fmt.Fprintf(output, "\nTools can also be generated from OpenAPI 3 specifications (see --openapi). Each operation becomes one tool: the name is\n")
+	fmt.Fprintf(output, "the operationId (or the method and the path), the path, query, header and cookie parameters become properties of the\n")
+	fmt.Fprintf(output, "input schema and the request body the property \"body\". Only local references ($ref: \"#/...\") are supported.\n")
+	fmt.Fprintf(output, "Request bodies are sent as JSON (preferred), form or multipart form. Operations whose body only supports other\n")
+	fmt.Fprintf(output, "multipart types will be skipped.\n")
+	fmt.Fprintf(output, "Safe methods (GET, HEAD, ...) are annotated as read-only, DELETE as destructive. Responses with a status code\n")
+	fmt.Fprintf(output, "other than 2xx will be reported as error result. By default each call needs the user's approval. Example:\n")
+	fmt.Fprintf(output, `
+  openapi:
+    petstore:
+      spec: /etc/mcp-system-control/petstore.yaml
+      baseUrl: https://petstore.example.com/v1
+      operations: ["listPets", "showPetById"]
+      prefix: "petstore_"
+      auth:
+        bearer: "${PETSTORE_TOKEN}"
+      timeout: 10s
+      approval: false
+`)
+
+	fmt.Fprintf(output, "\nTools can also be defined by JS plugin files (*.js) inside a plugin directory (see --plugins.dir).\n")
+	fmt.Fprintf(output, "Each file exports (%s.exports) one tool or a list of tools. The functions get the same variables as approval expressions:\n", expression.VarNameModule)
+	fmt.Fprintf(output, `
+  module.exports = {
+     `+expression.PluginFieldName+`: "greet",
+     `+expression.PluginFieldDescription+`: "Greets somebody.",
+     `+expression.PluginFieldInputSchema+`: { "type": "object", "properties": { "name": { "type": "string" } }, "required": ["name"] },
+     `+expression.PluginFieldAnnotations+`: { "readOnlyHint": true },
+     // a function, a boolean or an approval expression (optional)
+     `+expression.PluginFieldApproval+`: (ctx) => ctx.args.name === "root",
+     // the result will be treated like the result of a command expression
+     `+expression.PluginFieldHandler+`: (ctx) => "Hello " + ctx.args.name,
+  }
+`)
+	fmt.Fprintf(output, "The plugin files are loaded once at startup (they are not reloaded on changes).\n")
+
+	fmt.Fprintf(output, "\nTools can also be defined by WebAssembly modules (*.wasm) inside a wasm plugin directory (see --plugins.wasm.dir).\n")
+	fmt.Fprintf(output, "The modules run sandboxed (wasi without file system access) in a fresh instance per call. Data is exchanged as JSON;\n")
+	fmt.Fprintf(output, "a (ptr, len) pair is passed as two i32 values and returned packed as i64 (ptr << 32 | len). A module must export:\n")
+	fmt.Fprintf(output, `
+  memory
+  %s(size i32) -> ptr i32
+      allocates guest memory; the host writes its data into it
+  %s() -> i64
+      returns the tool definitions: [{ "name", "description", "inputSchema", "annotations", "approval" (boolean or expression) }]
+  %s(namePtr i32, nameLen i32, argsPtr i32, argsLen i32) -> i64
+      handles a tool call and returns { "result": any, "error": "..." }
+      the result will be treated like the result of a command expression
+`, wasm.ExportAlloc, wasm.ExportTools, wasm.ExportCall)
+	fmt.Fprintf(output, "\nThe host module %q provides the following functions. They take a JSON request (reqPtr i32, reqLen i32) and return\n", wasm.HostModule)
+	fmt.Fprintf(output, "a JSON response { \"result\": any, \"error\": \"...\" } (i64):\n")
+	fmt.Fprintf(output, `
+  %s
+      reads a file via the builtin tool %q (so its approval applies): { "path": "/path/to/file" }
+  %s
+      executes a command (see --plugins.wasm.runApproval): { "name": "ls", "arguments": ["-l"] }
+  %s
+      executes a http call (see --plugins.wasm.fetchApproval): { "method": "GET", "url": "https://example.com" }
+  %s
+      asks the user for approval: { "message": "Continue?", "details": "..." }
+  %s(msgPtr i32, msgLen i32)
+      writes the message into the log
+`, wasm.HostFuncReadFile, mcpFile.FileReadingTool.Name, wasm.HostFuncRun, wasm.HostFuncFetch, wasm.HostFuncApprove, wasm.HostFuncLog)
 }
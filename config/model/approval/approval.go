@@ -17,6 +17,9 @@ type Approval struct {
 	Requester RequesterType `yaml:"requester,omitempty" usage:"Requester type to use (auto, zenity, kdialog, notify-send, custom)"`
 	Language  string        `yaml:"language,omitempty" usage:"Language for approval messages (auto, en, de). Default: auto (system language)"`
 
+	ReadOnlyTools    string `yaml:"readOnlyTools,omitempty" usage:"Expression to check if user approval is needed for tools which are annotated as read-only (readOnlyHint). Overrides the approval of these tools"`
+	DestructiveTools string `yaml:"destructiveTools,omitempty" usage:"Expression to check if user approval is needed for tools which may be destructive (destructiveHint; default for all tools which are not read-only). Overrides the approval of these tools"`
+
 	// Tool-specific configurations
 	Zenity     ZenityConfig     `yaml:"zenity,omitempty" usage:"Zenity-specific: "`
 	KDialog    KDialogConfig    `yaml:"kdialog,omitempty" usage:"KDialog-specific: "`
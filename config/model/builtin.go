@@ -1,10 +1,13 @@
 package model
 
 import (
+	"fmt"
 	"mcp-system-control/approval"
 	"mcp-system-control/mcp/server/builtin/tools/command"
 	"mcp-system-control/mcp/server/builtin/tools/file"
 	"mcp-system-control/mcp/server/builtin/tools/system"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 type BuiltIns struct {
@@ -31,39 +34,59 @@ type BuiltIns struct {
 	CommandExec CommandExecution `yaml:"command-execution,omitempty" usage:"[Command execution] "`
 }
 
+type builtinTool struct {
+	tool     mcp.Tool
+	disable  *bool
+	approval *string
+}
+
+func (b *BuiltIns) tools() []builtinTool {
+	return []builtinTool{
+		{system.SystemInfoTool, &b.SystemInfo.Disable, &b.SystemInfo.Approval},
+		{system.EnvironmentTool, &b.Environment.Disable, &b.Environment.Approval},
+		{system.SystemTimeTool, &b.SystemTime.Disable, &b.SystemTime.Approval},
+		{file.StatsTool, &b.Stats.Disable, &b.Stats.Approval},
+		{file.ChangeModeTool, &b.ChangeMode.Disable, &b.ChangeMode.Approval},
+		{file.ChangeOwnerTool, &b.ChangeOwner.Disable, &b.ChangeOwner.Approval},
+		{file.ChangeTimesTool, &b.ChangeTimes.Disable, &b.ChangeTimes.Approval},
+		{file.FileCreationTool, &b.FileCreation.Disable, &b.FileCreation.Approval},
+		{file.FileTempCreationTool, &b.FileTempCreation.Disable, &b.FileTempCreation.Approval},
+		{file.FileAppendingTool, &b.FileAppending.Disable, &b.FileAppending.Approval},
+		{file.FileReadingTool, &b.FileReading.Disable, &b.FileReading.Approval},
+		{file.FileDeletionTool, &b.FileDeletion.Disable, &b.FileDeletion.Approval},
+		{file.DirectoryCreationTool, &b.DirectoryCreation.Disable, &b.DirectoryCreation.Approval},
+		{file.DirectoryTempCreationTool, &b.DirectoryTempCreation.Disable, &b.DirectoryTempCreation.Approval},
+		{file.DirectoryDeletionTool, &b.DirectoryDeletion.Disable, &b.DirectoryDeletion.Approval},
+		{command.CommandExecutionTool, &b.CommandExec.Disable, &b.CommandExec.Approval},
+	}
+}
+
+// Validate precompiles the approval expressions of all builtin tools.
+func (b *BuiltIns) Validate() error {
+	for _, bt := range b.tools() {
+		if err := approval.Approval(*bt.approval).Validate(); err != nil {
+			return fmt.Errorf("invalid approval expression for builtin tool '%s': %w", bt.tool.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ApplyPolicy disables the builtin tools which are not allowed by the policy and overrides their approvals.
+func (b *BuiltIns) ApplyPolicy(policy ToolPolicy) {
+	for _, bt := range b.tools() {
+		if !policy.Allows(bt.tool.Annotations) {
+			*bt.disable = true
+		}
+		*bt.approval = policy.ApprovalFor(bt.tool.Annotations, *bt.approval)
+	}
+}
+
 func (b *BuiltIns) GetApprovalFor(toolName string) string {
-	if system.SystemInfoTool.Name == toolName {
-		return b.SystemInfo.Approval
-	} else if system.EnvironmentTool.Name == toolName {
-		return b.Environment.Approval
-	} else if system.SystemTimeTool.Name == toolName {
-		return b.SystemTime.Approval
-	} else if file.StatsTool.Name == toolName {
-		return b.Stats.Approval
-	} else if file.ChangeModeTool.Name == toolName {
-		return b.ChangeMode.Approval
-	} else if file.ChangeOwnerTool.Name == toolName {
-		return b.ChangeOwner.Approval
-	} else if file.ChangeTimesTool.Name == toolName {
-		return b.ChangeTimes.Approval
-	} else if file.FileCreationTool.Name == toolName {
-		return b.FileCreation.Approval
-	} else if file.FileTempCreationTool.Name == toolName {
-		return b.FileTempCreation.Approval
-	} else if file.FileAppendingTool.Name == toolName {
-		return b.FileAppending.Approval
-	} else if file.FileReadingTool.Name == toolName {
-		return b.FileReading.Approval
-	} else if file.FileDeletionTool.Name == toolName {
-		return b.FileDeletion.Approval
-	} else if file.DirectoryCreationTool.Name == toolName {
-		return b.DirectoryCreation.Approval
-	} else if file.DirectoryTempCreationTool.Name == toolName {
-		return b.DirectoryTempCreation.Approval
-	} else if file.DirectoryDeletionTool.Name == toolName {
-		return b.DirectoryDeletion.Approval
-	} else if command.CommandExecutionTool.Name == toolName {
-		return b.CommandExec.Approval
+	for _, bt := range b.tools() {
+		if bt.tool.Name == toolName {
+			return *bt.approval
+		}
 	}
 
 	return approval.Always
@@ -2,14 +2,11 @@ package command
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log/slog"
-	"strings"
 
 	"mcp-system-control/mcp/server/builtin/tools/command"
 
-	"mvdan.cc/sh/v3/shell"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 type Command string
@@ -27,7 +24,11 @@ func (c Command) CommandFn(fd FunctionDefinition) CommandFn {
 		cmdDesc := command.CommandDescriptor{}
 		var err error
 
-		cmdDesc.Name, cmdDesc.Arguments, err = fd.GetCommandWithArgs(argsAsJson)
+		if len(fd.Steps) > 0 {
+			cmdDesc.Steps, err = fd.GetSteps(argsAsJson)
+		} else {
+			cmdDesc.Name, cmdDesc.Arguments, err = fd.GetCommandWithArgs(argsAsJson)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("error creating command for tool '%s': %w", fd.Name, err)
 		}
@@ -51,33 +52,58 @@ func (c Command) CommandFn(fd FunctionDefinition) CommandFn {
 			}
 		}
 
+		if fd.Stdin != "" {
+			cmdDesc.Stdin, err = fd.GetStdin(argsAsJson)
+			if err != nil {
+				return nil, fmt.Errorf("error creating stdin for tool '%s': %w", fd.Name, err)
+			}
+		}
+		cmdDesc.Output = fd.Output.settings()
+		cmdDesc.TimeoutMs = fd.Timeout.Milliseconds()
+		cmdDesc.GracePeriodMs = fd.GracePeriod.Milliseconds()
+		cmdDesc.SuccessExitCodes = fd.SuccessExitCodes
+		cmdDesc.FailOnExitCode = fd.FailOnExitCode
+
 		return cmdDesc.Run(ctx)
 	}
 }
 
-func (f *FunctionDefinition) GetCommandWithArgs(jsonArgs string) (string, []string, error) {
-	var data parsedArgs
-	if err := json.Unmarshal([]byte(jsonArgs), &data); err != nil {
-		return "", nil, fmt.Errorf("failed to parse JSON: %w", err)
-	}
+// ResultFn runs the command and applies the output post-processing of the function definition.
+// Unsuccessful exit codes and timeouts will be reported as error result.
+func (c Command) ResultFn(fd FunctionDefinition) ResultFn {
+	cmdFn := c.CommandFn(fd)
 
-	fields, err := shell.Fields(f.Command, func(varName string) string {
-		if varName == FunctionArgumentNameAll {
-			return jsonArgs
+	return func(ctx context.Context, argsAsJson string) (*mcp.CallToolResult, error) {
+		output, err := cmdFn(ctx, argsAsJson)
+		if err != nil {
+			result, err := command.ToolResult(output, err)
+			if err != nil {
+				return nil, err
+			}
+			return result, nil
 		}
-		r, err := data.Get(varName)
+
+		result, err := fd.Output.Process(output)
 		if err != nil {
-			slog.Error("Failed to marshal value",
-				"varName", varName,
-				"value", r,
-				"error", err,
-			)
+			return nil, fmt.Errorf("error processing output of tool '%s': %w", fd.Name, err)
 		}
-		return r
-	})
+		return result, nil
+	}
+}
+
+func (f *FunctionDefinition) GetCommandWithArgs(jsonArgs string) (string, []string, error) {
+	p, err := newPlaceholders(jsonArgs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fields, err := p.Fields(f.Command)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to parse command: %w", err)
 	}
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("empty command")
+	}
 	return fields[0], fields[1:], nil
 }
 
@@ -90,63 +116,44 @@ func (f *FunctionDefinition) GetAdditionalEnvironment(jsonArgs string) (map[stri
 }
 
 func processEnv(env map[string]string, jsonArgs string) (map[string]string, error) {
-	var data parsedArgs
-	if err := json.Unmarshal([]byte(jsonArgs), &data); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	p, err := newPlaceholders(jsonArgs)
+	if err != nil {
+		return nil, err
 	}
 
 	result := map[string]string{}
 	for key, value := range env {
-		for vk := range data {
-			v, err := data.Get(vk)
-			if err != nil {
-				return nil, err
-			}
-			value = strings.Replace(value, "$"+vk, v, -1)
+		result[key], err = p.Document(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse environment variable '%s': %w", key, err)
 		}
-		value = strings.Replace(value, "$@", jsonArgs, -1)
-		result[key] = value
 	}
 
 	return result, nil
 }
 
 func (f *FunctionDefinition) GetWorkingDirectory(jsonArgs string) (string, error) {
-	var data parsedArgs
-	if err := json.Unmarshal([]byte(jsonArgs), &data); err != nil {
-		return "", fmt.Errorf("failed to parse JSON: %w", err)
+	p, err := newPlaceholders(jsonArgs)
+	if err != nil {
+		return "", err
 	}
 
-	value := f.WorkingDir
-	for vk := range data {
-		v, err := data.Get(vk)
-		if err != nil {
-			return "", err
-		}
-		value = strings.Replace(value, "$"+vk, v, -1)
+	value, err := p.Document(f.WorkingDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse working directory: %w", err)
 	}
-
 	return value, nil
 }
 
-type parsedArgs map[string]interface{}
-
-func (p parsedArgs) Get(varName string) (string, error) {
-	varValue, exists := p[varName]
-	if !exists {
-		return "", nil
-	}
-
-	val, err := json.Marshal(varValue)
+func (f *FunctionDefinition) GetStdin(jsonArgs string) (string, error) {
+	p, err := newPlaceholders(jsonArgs)
 	if err != nil {
 		return "", err
 	}
-	sVal := string(val)
-	if len(sVal) > 0 && sVal[0] == '"' {
-		sVal = sVal[1:]
-	}
-	if len(sVal) > 0 && sVal[len(sVal)-1] == '"' {
-		sVal = sVal[:len(sVal)-1]
+
+	value, err := p.Document(f.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse stdin: %w", err)
 	}
-	return sVal, nil
+	return value, nil
 }
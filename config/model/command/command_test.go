@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFunctionDefinition_GetCommandWithArgs(t *testing.T) {
@@ -90,6 +92,88 @@ func TestFunctionDefinition_GetCommandWithArgs(t *testing.T) {
 			expectCmd:  "/usr/bin/echo",
 			expectArgs: []string{"hello", "world"},
 		},
+		{
+			command:    `/usr/bin/ls "${config.dir}" "${config.paths.1}"`,
+			args:       `{"config": {"dir": "/tmp", "paths": ["/a", "/b"]}}`,
+			expectCmd:  "/usr/bin/ls",
+			expectArgs: []string{"/tmp", "/b"},
+		},
+		{
+			command:    `/usr/bin/ls "${config.missing}"`,
+			args:       `{"config": {"dir": "/tmp"}}`,
+			expectCmd:  "/usr/bin/ls",
+			expectArgs: []string{""},
+		},
+		{
+			command:    `/usr/bin/run --mode "${mode:-fast}" --level "${config.level:-1}"`,
+			args:       `{}`,
+			expectCmd:  "/usr/bin/run",
+			expectArgs: []string{"--mode", "fast", "--level", "1"},
+		},
+		{
+			command:    `/usr/bin/run --mode "${mode:-fast}"`,
+			args:       `{"mode": "slow"}`,
+			expectCmd:  "/usr/bin/run",
+			expectArgs: []string{"--mode", "slow"},
+		},
+		{
+			command:     `/usr/bin/rm "${path:?}"`,
+			args:        `{}`,
+			expectError: true,
+		},
+		{
+			command:     `/usr/bin/rm "${config.path:?}"`,
+			args:        `{"config": {}}`,
+			expectError: true,
+		},
+		{
+			command:    `/usr/bin/rm "${path:?}"`,
+			args:       `{"path": "/tmp/file"}`,
+			expectCmd:  "/usr/bin/rm",
+			expectArgs: []string{"/tmp/file"},
+		},
+		{
+			command:    `/usr/bin/rm "${files[@]}"`,
+			args:       `{"files": ["/tmp/file 1", "/tmp/file2"]}`,
+			expectCmd:  "/usr/bin/rm",
+			expectArgs: []string{"/tmp/file 1", "/tmp/file2"},
+		},
+		{
+			command:    `/usr/bin/rm "${config.files[@]}"`,
+			args:       `{"config": {"files": [1, 2]}}`,
+			expectCmd:  "/usr/bin/rm",
+			expectArgs: []string{"1", "2"},
+		},
+		{
+			command:    `/usr/bin/rm "${files[@]}"`,
+			args:       `{}`,
+			expectCmd:  "/usr/bin/rm",
+			expectArgs: []string{},
+		},
+		{
+			command:    `/usr/bin/echo "$files"`,
+			args:       `{"files": ["a", "b"]}`,
+			expectCmd:  "/usr/bin/echo",
+			expectArgs: []string{`["a","b"]`},
+		},
+		{
+			command:    `/usr/bin/rm ${force:+--force} ${recursive:+-r} "$path"`,
+			args:       `{"force": true, "recursive": false, "path": "/tmp"}`,
+			expectCmd:  "/usr/bin/rm",
+			expectArgs: []string{"--force", "/tmp"},
+		},
+		{
+			command:    `/usr/bin/rm ${force:+--force} "$path"`,
+			args:       `{"path": "/tmp"}`,
+			expectCmd:  "/usr/bin/rm",
+			expectArgs: []string{"/tmp"},
+		},
+		{
+			command:    `/usr/bin/echo "$force"`,
+			args:       `{"force": false}`,
+			expectCmd:  "/usr/bin/echo",
+			expectArgs: []string{"false"},
+		},
 		{
 			command:     `/usr/bin/echo $msg`,
 			args:        `BROKEN_JSON`,
@@ -143,6 +227,30 @@ func TestFunctionDefinition_GetEnvironment(t *testing.T) {
 				"ENV1": `{"msg": "hello world"}`,
 			},
 		},
+		{
+			env: map[string]string{
+				"ENV1": "$path",
+				"ENV2": "$pathPrefix/bin",
+				"ENV3": "${config.dir:-/tmp}/${mode:-fast}",
+				"ENV4": "${files[@]}",
+				"ENV5": "${force:+--force}",
+			},
+			args: `{"path": "/usr", "pathPrefix": "/opt", "files": ["a", "b"], "force": false}`,
+			expectEnv: map[string]string{
+				"ENV1": "/usr",
+				"ENV2": "/opt/bin",
+				"ENV3": "/tmp/fast",
+				"ENV4": "a b",
+				"ENV5": "",
+			},
+		},
+		{
+			env: map[string]string{
+				"ENV1": "${path:?}",
+			},
+			args:        `{}`,
+			expectError: true,
+		},
 		{
 			env: map[string]string{
 				"USER": "rainu",
@@ -194,6 +302,16 @@ func TestFunctionDefinition_GetWorkingDirectory(t *testing.T) {
 			args:     `{"user":"rainu"}`,
 			expectWD: "/usr/rainu/home",
 		},
+		{
+			workDir:  "${config.root:-/home}/$user/${sub:?}",
+			args:     `{"user":"rainu", "sub": "projects"}`,
+			expectWD: "/home/rainu/projects",
+		},
+		{
+			workDir:     "/home/${user:?no user given}",
+			args:        `{}`,
+			expectError: true,
+		},
 		{
 			workDir:     "/usr/$user/home",
 			args:        `BROKEN_JSON`,
@@ -217,6 +335,16 @@ func TestFunctionDefinition_GetWorkingDirectory(t *testing.T) {
 	}
 }
 
+func TestFunctionDefinition_RequiredError(t *testing.T) {
+	fn := &FunctionDefinition{Command: `/usr/bin/rm "${config.path:?}"`}
+	_, _, err := fn.GetCommandWithArgs(`{}`)
+	assert.EqualError(t, err, "failed to parse command: missing required argument 'config.path'")
+
+	fn = &FunctionDefinition{WorkingDir: `${dir:?the directory is required}`}
+	_, err = fn.GetWorkingDirectory(`{}`)
+	assert.EqualError(t, err, "failed to parse working directory: missing required argument 'dir': the directory is required")
+}
+
 func TestCommand_CommandFn(t *testing.T) {
 	testFD := FunctionDefinition{
 		Command: `echo "$message"`,
@@ -229,3 +357,74 @@ func TestCommand_CommandFn(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Hello World", strings.TrimSpace(string(result)))
 }
+
+func TestCommand_CommandFn_Stdin(t *testing.T) {
+	tests := []struct {
+		stdin    string
+		args     string
+		expected string
+	}{
+		{`$content`, `{"content": "SELECT 1;\nSELECT 2;"}`, "SELECT 1;\nSELECT 2;"},
+		{`$@`, `{"content": "hello"}`, `{"content": "hello"}`},
+		{`${config.content:-default}`, `{}`, "default"},
+	}
+
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			testFD := FunctionDefinition{
+				Command: `cat`,
+				Stdin:   tc.stdin,
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := Command(testFD.Command).CommandFn(testFD)(ctx, tc.args)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestCommand_CommandFn_Stdin_MissingRequired(t *testing.T) {
+	testFD := FunctionDefinition{
+		Name:    "psql",
+		Command: `cat`,
+		Stdin:   `${query:?}`,
+	}
+
+	_, err := Command(testFD.Command).CommandFn(testFD)(context.Background(), `{}`)
+	assert.EqualError(t, err, "error creating stdin for tool 'psql': failed to parse stdin: missing required argument 'query'")
+}
+
+func TestCommand_ResultFn_FailOnExitCode(t *testing.T) {
+	fd := FunctionDefinition{
+		Name:           "test",
+		Command:        `sh -c "echo out; echo err >&2; exit $code"`,
+		FailOnExitCode: true,
+	}
+
+	result, err := Command(fd.Command).ResultFn(fd)(context.Background(), `{"code": 2}`)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("command exited with code 2\nstderr:\nerr\n")}, result.Content)
+
+	fd.SuccessExitCodes = []int{0, 2}
+	result, err = Command(fd.Command).ResultFn(fd)(context.Background(), `{"code": 2}`)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("out\nerr\n")}, result.Content)
+}
+
+func TestCommand_ResultFn_Timeout(t *testing.T) {
+	fd := FunctionDefinition{
+		Name:    "test",
+		Command: `sleep 10`,
+		Timeout: 100 * time.Millisecond,
+	}
+
+	result, err := Command(fd.Command).ResultFn(fd)(context.Background(), `{}`)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("command timed out after 100ms")}, result.Content)
+}
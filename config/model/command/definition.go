@@ -3,13 +3,16 @@ package command
 import (
 	"context"
 	"mcp-system-control/approval"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/santhosh-tekuri/jsonschema/v6"
 )
 
 const FunctionArgumentNameAll = "@"
 
 type CommandFn func(ctx context.Context, jsonArguments string) ([]byte, error)
+type ResultFn func(ctx context.Context, jsonArguments string) (*mcp.CallToolResult, error)
 type ApprovalFn func(ctx context.Context, jsonArguments string) bool
 
 type FunctionDefinition struct {
@@ -17,16 +20,30 @@ type FunctionDefinition struct {
 	Description string              `yaml:"description,omitempty" json:"description" usage:"The description of the function"`
 	Parameters  mcp.ToolInputSchema `yaml:"parameters,omitempty" json:"parameters" usage:"The parameter definition of the function"`
 	Approval    string              `yaml:"approval,omitempty" json:"approval" usage:"Expression to check if user approval is needed before execute this tool"`
+	Annotations mcp.ToolAnnotation  `yaml:"annotations,omitempty" json:"annotations,omitempty" usage:"Hints about the behavior of the tool"`
 
 	Command               string            `yaml:"command,omitempty,omitempty" json:"command,omitempty" usage:"The command to execute. This is a format string with placeholders for the parameters. Example: /usr/bin/touch $path"`
+	Steps                 Steps             `yaml:"steps,omitempty" json:"steps,omitempty" usage:"Commands which will be piped together (the stdout of a step is the stdin of the next step). Can be used instead of command: "`
 	CommandExpr           string            `yaml:"commandExpr,omitempty,omitempty" json:"commandExpr,omitempty" usage:"JavaScript expression (or path to JS-file) to execute. See Tool-Help (--help-tool) for more information."`
 	Environment           map[string]string `yaml:"env,omitempty,omitempty" json:"env,omitempty" usage:"Environment variables to pass to the command (will overwrite the default environment)"`
 	AdditionalEnvironment map[string]string `yaml:"additionalEnv,omitempty,omitempty" json:"additionalEnv,omitempty" usage:"Additional environment variables to pass to the command (will be merged with the default environment)"`
 	WorkingDir            string            `yaml:"workingDir,omitempty,omitempty" json:"workingDir,omitempty" usage:"The working directory for the command"`
+	Stdin                 string            `yaml:"stdin,omitempty" json:"stdin,omitempty" usage:"The input for the command. This is a format string with placeholders for the parameters. Example: $content"`
+	IncludeLog            bool              `yaml:"includeLog,omitempty" json:"includeLog,omitempty" usage:"Append the log output of the command expression to the tool result"`
+	Output                Output            `yaml:"output,omitempty" json:"output,omitzero" usage:"Post-processing of the command output: "`
+	Timeout               time.Duration     `yaml:"timeout,omitempty" json:"timeout,omitempty" usage:"The maximum execution time of the command (or http call). After that, the command will be terminated (SIGTERM)"`
+	GracePeriod           time.Duration     `yaml:"gracePeriod,omitempty" json:"gracePeriod,omitempty" usage:"The time between the termination (SIGTERM) and the kill (SIGKILL) of a timed out command (default: 5s)"`
+	SuccessExitCodes      []int             `yaml:"successExitCodes,omitempty" json:"successExitCodes,omitempty" usage:"The exit codes which are treated as success (default: 0). Only relevant in combination with failOnExitCode"`
+	FailOnExitCode        bool              `yaml:"failOnExitCode,omitempty" json:"failOnExitCode,omitempty" usage:"Report an unsuccessful exit code as error result (including the exit code and the end of stderr)"`
+	HTTP                  HTTP              `yaml:"http,omitempty" json:"http,omitzero" usage:"A http call which will be executed instead of a command: "`
 
 	//will be filled at runtime (and should not be filled by user in any way)
 	CommandFn  CommandFn  `yaml:"-" json:"-"`
+	ResultFn   ResultFn   `yaml:"-" json:"-"`
 	ApprovalFn ApprovalFn `yaml:"-" json:"-"`
+
+	// the compiled parameters (see CompileSchema)
+	schema *jsonschema.Schema
 }
 
 func (f *FunctionDefinition) NeedApproval(ctx context.Context, jsonArgs string) bool {
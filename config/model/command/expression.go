@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"mcp-system-control/expression"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 type Expression string
@@ -34,3 +36,29 @@ func (c Expression) CommandFn(fd FunctionDefinition) CommandFn {
 		return result, nil
 	}
 }
+
+func (c Expression) ResultFn(fd FunctionDefinition) ResultFn {
+	return func(ctx context.Context, args string) (*mcp.CallToolResult, error) {
+		var logs *expression.LogCollector
+		if fd.IncludeLog {
+			ctx, logs = expression.WithLogCollector(ctx)
+		}
+
+		result, err := expression.Run(ctx, string(c), Variables{
+			FunctionDefinition: fd,
+			Arguments:          args,
+		}).AsToolResult()
+		if err != nil {
+			if logs != nil && len(logs.Lines()) > 0 {
+				return nil, fmt.Errorf("error running expression: %w\nLog:\n%s", err, logs.String())
+			}
+			return nil, fmt.Errorf("error running expression: %w", err)
+		}
+
+		if logs != nil && len(logs.Lines()) > 0 {
+			result.Content = append(result.Content, mcp.NewTextContent("Log:\n"+logs.String()))
+		}
+
+		return result, nil
+	}
+}
@@ -39,15 +39,50 @@ func TestCommandExpression_CommandFn(t *testing.T) {
 				Required: []string{"path"},
 			},
 			Approval: "false",
+			Annotations: mcp.ToolAnnotation{
+				Title:           "Test",
+				ReadOnlyHint:    mcp.ToBoolPtr(true),
+				DestructiveHint: mcp.ToBoolPtr(false),
+				IdempotentHint:  mcp.ToBoolPtr(true),
+				OpenWorldHint:   mcp.ToBoolPtr(false),
+			},
 			Environment: map[string]string{
 				"TEST_ENV": "test",
 			},
 			AdditionalEnvironment: map[string]string{
 				"ADDITIONAL_ENV_VAR": "value",
 			},
-			WorkingDir:  "/home/test",
-			Command:     "EMPTY",
+			WorkingDir: "/home/test",
+			Stdin:      "$path",
+			IncludeLog: true,
+			Output: Output{
+				Stream:     OutputStreamStdout,
+				Regex:      "^(.*)$",
+				Jq:         ".name",
+				Structured: true,
+				FirstLines: 1,
+				LastLines:  2,
+				FirstBytes: 3,
+				LastBytes:  4,
+			},
+			Timeout:          5 * time.Second,
+			GracePeriod:      time.Second,
+			SuccessExitCodes: []int{0, 1},
+			FailOnExitCode:   true,
+			Command:          "EMPTY",
+			Steps: Steps{
+				{Command: "cat $path", ContinueOnError: true},
+			},
 			CommandExpr: string(toTest),
+			HTTP: HTTP{
+				Method:         "POST",
+				Url:            "https://example.com/$path",
+				Query:          map[string]string{"q": "$path"},
+				Header:         map[string]string{"X-Path": "$path"},
+				Body:           `{"path": "$path"}`,
+				ExpectedStatus: []int{200, 201},
+				Extract:        ".name",
+			},
 		},
 		Arguments: `{"path": "/tmp/"}`,
 	}
@@ -313,3 +348,139 @@ JSON.stringify(result)
 		Body: `{"message":"Success"}`,
 	}, parsedResult)
 }
+
+func TestCommandExpression_ResultFn(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   *mcp.CallToolResult
+		expectErr  bool
+	}{
+		{
+			name:       "plain string",
+			expression: `"hello world"`,
+			expected:   mcp.NewToolResultText("hello world"),
+		},
+		{
+			name:       "number",
+			expression: `42`,
+			expected:   mcp.NewToolResultText("42"),
+		},
+		{
+			name:       "object without result fields",
+			expression: `({"message": "hello"})`,
+			expected:   mcp.NewToolResultText("[object Object]"),
+		},
+		{
+			name: "multi part",
+			expression: `({
+	content: [
+		"first",
+		{type: "text", text: "second"},
+		{type: "image", data: "aGVsbG8=", mimeType: "image/png"},
+		{type: "resource_link", uri: "file:///tmp/test", name: "test"},
+		{type: "resource", resource: {uri: "file:///tmp/blob", mimeType: "application/octet-stream", blob: "aGVsbG8="}},
+	]
+})`,
+			expected: &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.NewTextContent("first"),
+					mcp.NewTextContent("second"),
+					mcp.NewImageContent("aGVsbG8=", "image/png"),
+					mcp.NewResourceLink("file:///tmp/test", "test", "", ""),
+					mcp.NewEmbeddedResource(mcp.BlobResourceContents{URI: "file:///tmp/blob", MIMEType: "application/octet-stream", Blob: "aGVsbG8="}),
+				},
+			},
+		},
+		{
+			name:       "structured content only",
+			expression: `({structuredContent: {"count": 2}})`,
+			expected: &mcp.CallToolResult{
+				Content:           []mcp.Content{mcp.NewTextContent(`{"count":2}`)},
+				StructuredContent: map[string]any{"count": float64(2)},
+			},
+		},
+		{
+			name:       "error result",
+			expression: `({isError: true, content: ["something went wrong"]})`,
+			expected: &mcp.CallToolResult{
+				Content: []mcp.Content{mcp.NewTextContent("something went wrong")},
+				IsError: true,
+			},
+		},
+		{
+			name:       "invalid content",
+			expression: `({content: "not an array"})`,
+			expectErr:  true,
+		},
+		{
+			name:       "invalid content type",
+			expression: `({content: [{type: "unknown"}]})`,
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ce := Expression(tt.expression)
+			require.NoError(t, ce.Validate())
+
+			result, err := ce.ResultFn(FunctionDefinition{})(context.Background(), "{}")
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCommandExpression_ResultFn_IncludeLog(t *testing.T) {
+	ce := Expression(`log("first"); console.warn("second", 2); "result"`)
+	require.NoError(t, ce.Validate())
+
+	result, err := ce.ResultFn(FunctionDefinition{IncludeLog: true})(context.Background(), "{}")
+	require.NoError(t, err)
+	assert.Equal(t, []mcp.Content{
+		mcp.NewTextContent("result"),
+		mcp.NewTextContent("Log:\n[info] first\n[warning] second 2"),
+	}, result.Content)
+
+	result, err = ce.ResultFn(FunctionDefinition{})(context.Background(), "{}")
+	require.NoError(t, err)
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("result")}, result.Content)
+
+	ce = Expression(`log("before error"); throw new Error("boom")`)
+	require.NoError(t, ce.Validate())
+
+	_, err = ce.ResultFn(FunctionDefinition{IncludeLog: true})(context.Background(), "{}")
+	assert.ErrorContains(t, err, "boom")
+	assert.ErrorContains(t, err, "Log:\n[info] before error")
+}
+
+func TestCommandExpression_ResultFn_StackTrace(t *testing.T) {
+	tmp, err := os.CreateTemp("", "mcp-system-control-test.*.js")
+	require.NoError(t, err)
+	defer os.Remove(tmp.Name())
+
+	require.NoError(t, os.WriteFile(tmp.Name(), []byte(`function inner() {
+	throw new Error("boom")
+}
+function outer() {
+	inner()
+}
+outer()
+`), 0666))
+
+	ce := Expression(tmp.Name())
+	require.NoError(t, ce.Validate())
+
+	_, err = ce.ResultFn(FunctionDefinition{})(context.Background(), "{}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Error: boom")
+	assert.Contains(t, err.Error(), "at inner ("+tmp.Name()+":2:8")
+	assert.Contains(t, err.Error(), "at outer ("+tmp.Name()+":5:7")
+	assert.Contains(t, err.Error(), "at "+tmp.Name()+":7:6")
+}
@@ -0,0 +1,196 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp-system-control/mcp/server/builtin/tools/http"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type HTTP struct {
+	Method         string            `yaml:"method,omitempty" json:"method,omitempty" usage:"The http method (default: GET). This is a format string with placeholders for the parameters"`
+	Url            string            `yaml:"url,omitempty" json:"url,omitempty" usage:"The url of the call. This is a format string with placeholders for the parameters (the values will be url-encoded). Example: https://api.example.com/users/${id}"`
+	Query          map[string]string `yaml:"query,omitempty" json:"query,omitempty" usage:"Query parameters (will be url-encoded). The values are format strings with placeholders for the parameters"`
+	Header         map[string]string `yaml:"header,omitempty" json:"header,omitempty" usage:"Headers of the call. The values are format strings with placeholders for the parameters"`
+	Body           string            `yaml:"body,omitempty" json:"body,omitempty" usage:"The body of the call (default content type: application/json). This is a format string with placeholders for the parameters. For JSON, string values will be escaped and all other values inserted as JSON. Example: {\"name\": \"$name\", \"tags\": $tags}"`
+	ExpectedStatus []int             `yaml:"expectedStatus,omitempty" json:"expectedStatus,omitempty" usage:"The status codes which are treated as success (default: 2xx). All others will be reported as error result"`
+	Extract        string            `yaml:"extract,omitempty" json:"extract,omitempty" usage:"jq-style path to extract from the response (which must be JSON). Example: .items[].name"`
+
+	extract Output
+}
+
+// IsSet returns true if a http call is configured.
+func (h *HTTP) IsSet() bool {
+	return h.Url != ""
+}
+
+// Validate checks the http call and compiles the extraction path.
+func (h *HTTP) Validate() error {
+	if h.Url == "" {
+		return fmt.Errorf("empty url")
+	}
+	for _, status := range h.ExpectedStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("invalid expected status code %d", status)
+		}
+	}
+
+	h.extract = Output{Jq: h.Extract}
+	if ve := h.extract.Validate(); ve != nil {
+		return fmt.Errorf("invalid extraction path: %w", ve)
+	}
+	return nil
+}
+
+func (h *HTTP) isExpected(statusCode int) bool {
+	if len(h.ExpectedStatus) == 0 {
+		return statusCode >= 200 && statusCode <= 299
+	}
+	for _, status := range h.ExpectedStatus {
+		if status == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCall creates the http call by replacing the placeholders with the given arguments.
+func (f *FunctionDefinition) GetCall(jsonArgs string) (*http.CallDescriptor, error) {
+	p, err := newPlaceholders(jsonArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	call := &http.CallDescriptor{
+		Method:    "GET",
+		Header:    map[string]string{},
+		TimeoutMs: f.Timeout.Milliseconds(),
+	}
+
+	if f.HTTP.Method != "" {
+		method, err := p.Document(f.HTTP.Method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse method: %w", err)
+		}
+		call.Method = strings.ToUpper(strings.TrimSpace(method))
+	}
+	if call.Url, err = p.DocumentEscaped(f.HTTP.Url, escapeUrlValue); err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+	if len(f.HTTP.Query) > 0 {
+		call.Query = map[string]any{}
+		for key, value := range f.HTTP.Query {
+			if call.Query[key], err = p.Document(value); err != nil {
+				return nil, fmt.Errorf("failed to parse query parameter '%s': %w", key, err)
+			}
+		}
+	}
+	for key, value := range f.HTTP.Header {
+		if call.Header[key], err = p.Document(value); err != nil {
+			return nil, fmt.Errorf("failed to parse header '%s': %w", key, err)
+		}
+	}
+	if f.HTTP.Body != "" {
+		contentType := contentTypeOf(call.Header)
+		if contentType == "" {
+			contentType = "application/json"
+			call.Header["Content-Type"] = contentType
+		}
+		if call.StringBody, err = p.DocumentEscaped(f.HTTP.Body, bodyEscaper(contentType)); err != nil {
+			return nil, fmt.Errorf("failed to parse body: %w", err)
+		}
+	}
+
+	return call, nil
+}
+
+// urlValueEscaper escapes all characters which would change the structure of the url. The result is
+// valid in the path as well as in the query.
+var urlValueEscaper = strings.NewReplacer("&", "%26", "=", "%3D", "+", "%2B", ";", "%3B")
+
+func escapeUrlValue(value any) (string, error) {
+	s, err := toString(value)
+	if err != nil {
+		return "", err
+	}
+	return urlValueEscaper.Replace(url.PathEscape(s)), nil
+}
+
+// escapeJsonValue escapes strings for the usage inside a JSON string. All other values
+// (numbers, booleans, arrays and objects) will be inserted as JSON.
+func escapeJsonValue(value any) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	if _, isString := value.(string); isString {
+		return string(raw[1 : len(raw)-1]), nil
+	}
+	return string(raw), nil
+}
+
+func escapeFormValue(value any) (string, error) {
+	s, err := toString(value)
+	if err != nil {
+		return "", err
+	}
+	return url.QueryEscape(s), nil
+}
+
+// bodyEscaper returns the escape function for the values which are inserted into a body of the given content type.
+func bodyEscaper(contentType string) func(value any) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return escapeJsonValue
+	case mediaType == "application/x-www-form-urlencoded":
+		return escapeFormValue
+	}
+	return nil
+}
+
+func contentTypeOf(header map[string]string) string {
+	for key, value := range header {
+		if strings.EqualFold(key, "Content-Type") {
+			return value
+		}
+	}
+	return ""
+}
+
+// HTTPResultFn executes the http call of the function definition. Unexpected status codes will be
+// reported as error result, otherwise the (extracted) response body will be returned.
+func HTTPResultFn(fd FunctionDefinition) ResultFn {
+	return func(ctx context.Context, argsAsJson string) (*mcp.CallToolResult, error) {
+		call, err := fd.GetCall(argsAsJson)
+		if err != nil {
+			return nil, fmt.Errorf("error creating http call for tool '%s': %w", fd.Name, err)
+		}
+
+		result, err := call.Run(ctx, http.ClientFor(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("error executing http call for tool '%s': %w", fd.Name, err)
+		}
+		if !fd.HTTP.isExpected(result.StatusCode) {
+			return httpErrorResult(result), nil
+		}
+
+		body := httpBody(result)
+		if fd.HTTP.Extract != "" {
+			extracted, err := fd.HTTP.extract.Process([]byte(body))
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			body = extracted.Content[0].(mcp.TextContent).Text
+		}
+		if fd.Output.IsSet() {
+			return fd.Output.Process([]byte(body))
+		}
+		return mcp.NewToolResultText(body), nil
+	}
+}
@@ -0,0 +1,211 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionDefinition_GetCall(t *testing.T) {
+	toTest := FunctionDefinition{
+		Timeout: 5 * time.Second,
+		HTTP: HTTP{
+			Method: "${method:-post}",
+			Url:    "https://api.example.com/users/${user.id}",
+			Query:  map[string]string{"verbose": "${verbose:+yes}", "q": "$query"},
+			Header: map[string]string{"Authorization": "Bearer $token"},
+			Body:   `{"name": "${user.name}", "tags": $tags}`,
+		},
+	}
+
+	call, err := toTest.GetCall(`{"user": {"id": 1, "name": "John Doe"}, "verbose": true, "query": "a b", "token": "secret", "tags": ["a", "b"]}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "POST", call.Method)
+	assert.Equal(t, "https://api.example.com/users/1", call.Url)
+	assert.Equal(t, map[string]any{"verbose": "yes", "q": "a b"}, call.Query)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer secret", "Content-Type": "application/json"}, call.Header)
+	assert.Equal(t, `{"name": "John Doe", "tags": ["a","b"]}`, call.StringBody)
+	assert.Equal(t, int64(5000), call.TimeoutMs)
+}
+
+func TestFunctionDefinition_GetCall_Escaping(t *testing.T) {
+	tests := []struct {
+		name         string
+		http         HTTP
+		args         string
+		expectedUrl  string
+		expectedBody string
+	}{
+		{
+			name:        "url path",
+			http:        HTTP{Url: "https://api.example.com/users/${id}/repos?page=$page"},
+			args:        `{"id": "../admin?role=admin&x=1#", "page": "1&admin=true"}`,
+			expectedUrl: "https://api.example.com/users/..%2Fadmin%3Frole%3Dadmin%26x%3D1%23/repos?page=1%26admin%3Dtrue",
+		},
+		{
+			name:         "json body",
+			http:         HTTP{Url: "https://example.com", Body: `{"title": "$title", "labels": $labels, "count": $count}`},
+			args:         `{"title": "a\", \"admin\": true, \"x\": \"\n", "labels": ["bug", "\"x"], "count": 2}`,
+			expectedUrl:  "https://example.com",
+			expectedBody: `{"title": "a\", \"admin\": true, \"x\": \"\n", "labels": ["bug","\"x"], "count": 2}`,
+		},
+		{
+			name:         "form body",
+			http:         HTTP{Url: "https://example.com", Header: map[string]string{"content-type": "application/x-www-form-urlencoded"}, Body: `name=$name&role=user`},
+			args:         `{"name": "john&role=admin"}`,
+			expectedUrl:  "https://example.com",
+			expectedBody: `name=john%26role%3Dadmin&role=user`,
+		},
+		{
+			name:         "plain body",
+			http:         HTTP{Url: "https://example.com", Header: map[string]string{"Content-Type": "text/plain"}, Body: `Hello $name`},
+			args:         `{"name": "\"John\" & co"}`,
+			expectedUrl:  "https://example.com",
+			expectedBody: `Hello "John" & co`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toTest := FunctionDefinition{HTTP: tt.http}
+
+			call, err := toTest.GetCall(tt.args)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedUrl, call.Url)
+			assert.Equal(t, tt.expectedBody, call.StringBody)
+			if strings.HasPrefix(tt.name, "json") {
+				var body map[string]any
+				require.NoError(t, json.Unmarshal([]byte(call.StringBody), &body))
+				assert.Len(t, body, 3)
+				assert.Equal(t, "a\", \"admin\": true, \"x\": \"\n", body["title"])
+			}
+		})
+	}
+}
+
+func TestFunctionDefinition_GetCall_Defaults(t *testing.T) {
+	toTest := FunctionDefinition{HTTP: HTTP{Url: "https://example.com"}}
+
+	call, err := toTest.GetCall(`{}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "GET", call.Method)
+	assert.Equal(t, "https://example.com", call.Url)
+	assert.Nil(t, call.Query)
+	assert.Empty(t, call.StringBody)
+}
+
+func TestFunctionDefinition_GetCall_Missing(t *testing.T) {
+	toTest := FunctionDefinition{HTTP: HTTP{Url: "https://example.com/${id:?}"}}
+
+	_, err := toTest.GetCall(`{}`)
+	assert.EqualError(t, err, "failed to parse url: missing required argument 'id'")
+}
+
+func TestHTTP_Validate(t *testing.T) {
+	assert.EqualError(t, (&HTTP{}).Validate(), "empty url")
+	assert.EqualError(t, (&HTTP{Url: "http://localhost", ExpectedStatus: []int{99}}).Validate(), "invalid expected status code 99")
+	assert.ErrorContains(t, (&HTTP{Url: "http://localhost", Extract: ".["}).Validate(), "invalid extraction path")
+	assert.NoError(t, (&HTTP{Url: "http://localhost", ExpectedStatus: []int{404}, Extract: ".items[]"}).Validate())
+}
+
+func TestHTTPResultFn(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, `{"name": "John"}`, string(body))
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 1, "name": "John"}`))
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`gone`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not found`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		http     HTTP
+		output   Output
+		args     string
+		expected string
+		isError  bool
+	}{
+		{
+			name:     "success",
+			http:     HTTP{Method: "POST", Url: server.URL + "/users", Body: `{"name": "$name"}`},
+			args:     `{"name": "John"}`,
+			expected: `{"id": 1, "name": "John"}`,
+		},
+		{
+			name:     "extract",
+			http:     HTTP{Method: "POST", Url: server.URL + "/users", Body: `{"name": "$name"}`, Extract: ".name"},
+			args:     `{"name": "John"}`,
+			expected: `John`,
+		},
+		{
+			name:     "extract and output",
+			http:     HTTP{Method: "POST", Url: server.URL + "/users", Body: `{"name": "$name"}`, Extract: ".name"},
+			output:   Output{Regex: "^J(.*)$"},
+			args:     `{"name": "John"}`,
+			expected: `ohn`,
+		},
+		{
+			name:     "unexpected status",
+			http:     HTTP{Url: server.URL + "/$path"},
+			args:     `{"path": "unknown"}`,
+			expected: "404 Not Found\nnot found",
+			isError:  true,
+		},
+		{
+			name:     "expected status",
+			http:     HTTP{Url: server.URL + "/gone", ExpectedStatus: []int{410}},
+			args:     `{}`,
+			expected: "gone",
+		},
+		{
+			name:     "extract invalid json",
+			http:     HTTP{Url: server.URL + "/gone", ExpectedStatus: []int{410}, Extract: ".name"},
+			args:     `{}`,
+			expected: "output is not valid JSON: invalid character 'g' looking for beginning of value",
+			isError:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd := FunctionDefinition{Name: "test", HTTP: tt.http, Output: tt.output}
+			require.NoError(t, fd.HTTP.Validate())
+			require.NoError(t, fd.Output.Validate())
+
+			result, err := HTTPResultFn(fd)(context.Background(), tt.args)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.isError, result.IsError)
+			assert.Equal(t, tt.expected, result.Content[0].(mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestHTTPResultFn_InvalidArguments(t *testing.T) {
+	fd := FunctionDefinition{Name: "test", HTTP: HTTP{Url: "http://localhost/${id:?}"}}
+
+	_, err := HTTPResultFn(fd)(context.Background(), `{}`)
+	assert.EqualError(t, err, "error creating http call for tool 'test': failed to parse url: missing required argument 'id'")
+}
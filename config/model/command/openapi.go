@@ -0,0 +1,112 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp-system-control/mcp/server/builtin/tools/http"
+	"mcp-system-control/openapi"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// OpenAPIDefinition converts the operation of an OpenAPI specification into a function definition.
+func OpenAPIDefinition(op openapi.Operation, settings openapi.Settings, approval string) (FunctionDefinition, error) {
+	fd := FunctionDefinition{
+		Name:        op.Name,
+		Description: operationDescription(op),
+		Approval:    approval,
+		Annotations: operationAnnotations(op),
+	}
+
+	if err := convert(op.InputSchema, &fd.Parameters); err != nil {
+		return fd, fmt.Errorf("invalid input schema of operation '%s': %w", op.Name, err)
+	}
+
+	fd.ResultFn = func(ctx context.Context, jsonArguments string) (*mcp.CallToolResult, error) {
+		var args map[string]any
+		if err := json.Unmarshal([]byte(jsonArguments), &args); err != nil {
+			return nil, fmt.Errorf("failed to parse arguments: %w", err)
+		}
+
+		call, err := op.Request(settings, args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := call.Run(ctx, http.ClientFor(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("error executing call of operation '%s': %w", op.Name, err)
+		}
+		return httpToolResult(result), nil
+	}
+
+	return fd, nil
+}
+
+func operationDescription(op openapi.Operation) string {
+	var parts []string
+	if op.Summary != "" {
+		parts = append(parts, op.Summary)
+	}
+	if op.Description != "" && op.Description != op.Summary {
+		parts = append(parts, op.Description)
+	}
+	if len(parts) == 0 {
+		parts = append(parts, op.Method+" "+op.Path)
+	}
+	if op.Deprecated {
+		parts = append(parts, "Deprecated: this operation should not be used anymore.")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// operationAnnotations derives the hints of the tool by the semantic of the http method.
+func operationAnnotations(op openapi.Operation) mcp.ToolAnnotation {
+	annotations := mcp.ToolAnnotation{
+		Title:         op.Summary,
+		OpenWorldHint: mcp.ToBoolPtr(true),
+	}
+
+	switch op.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		annotations.ReadOnlyHint = mcp.ToBoolPtr(true)
+	case "PUT":
+		annotations.ReadOnlyHint = mcp.ToBoolPtr(false)
+		annotations.IdempotentHint = mcp.ToBoolPtr(true)
+	case "DELETE":
+		annotations.ReadOnlyHint = mcp.ToBoolPtr(false)
+		annotations.DestructiveHint = mcp.ToBoolPtr(true)
+		annotations.IdempotentHint = mcp.ToBoolPtr(true)
+	default:
+		annotations.ReadOnlyHint = mcp.ToBoolPtr(false)
+	}
+	return annotations
+}
+
+// httpToolResult converts the result of a http call into a tool result. Only successful calls (2xx)
+// return the body, all others will be reported as error result (including status and body).
+func httpToolResult(result *http.CallResult) *mcp.CallToolResult {
+	if result.StatusCode < 200 || result.StatusCode > 299 {
+		return httpErrorResult(result)
+	}
+	return mcp.NewToolResultText(httpBody(result))
+}
+
+// httpErrorResult reports the status and the body of the call as error result.
+func httpErrorResult(result *http.CallResult) *mcp.CallToolResult {
+	message := result.Status
+	if body := httpBody(result); body != "" {
+		message += "\n" + body
+	}
+	return mcp.NewToolResultError(message)
+}
+
+// httpBody returns the body of the response (base64 encoded if it is binary).
+func httpBody(result *http.CallResult) string {
+	if result.BodyBase64 != "" {
+		return result.BodyBase64
+	}
+	return result.Body
+}
@@ -0,0 +1,109 @@
+package command
+
+import (
+	"context"
+	"io"
+	"mcp-system-control/openapi"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDefinition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/pets/1":
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"name":"Tom"}`, string(body))
+			assert.Equal(t, "dry", r.URL.Query().Get("mode"))
+
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id":1,"name":"Tom"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not found`))
+		}
+	}))
+	defer server.Close()
+
+	op := openapi.Operation{
+		Name:    "updatePet",
+		Method:  http.MethodPut,
+		Path:    "/pets/{petId}",
+		Summary: "Update a pet",
+		Parameters: []openapi.Parameter{
+			{Name: "petId", In: openapi.ParameterInPath, Property: "petId", Required: true},
+			{Name: "mode", In: openapi.ParameterInQuery, Property: "mode"},
+		},
+		Body: &openapi.Body{ContentType: "application/json", Property: openapi.BodyProperty},
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"petId": map[string]any{"type": "string"},
+				"mode":  map[string]any{"type": "string"},
+				"body":  map[string]any{"type": "object"},
+			},
+			"required": []string{"petId"},
+		},
+	}
+	settings := openapi.Settings{
+		BaseUrl: server.URL,
+		Header:  map[string]string{"Authorization": "Bearer token"},
+	}
+
+	fd, err := OpenAPIDefinition(op, settings, "true")
+	require.NoError(t, err)
+
+	assert.Equal(t, "updatePet", fd.Name)
+	assert.Equal(t, "Update a pet", fd.Description)
+	assert.Equal(t, "true", fd.Approval)
+	assert.Equal(t, mcp.ToolInputSchema{
+		Type: "object",
+		Properties: map[string]any{
+			"petId": map[string]any{"type": "string"},
+			"mode":  map[string]any{"type": "string"},
+			"body":  map[string]any{"type": "object"},
+		},
+		Required: []string{"petId"},
+	}, fd.Parameters)
+	assert.Equal(t, mcp.ToolAnnotation{
+		Title:          "Update a pet",
+		ReadOnlyHint:   mcp.ToBoolPtr(false),
+		IdempotentHint: mcp.ToBoolPtr(true),
+		OpenWorldHint:  mcp.ToBoolPtr(true),
+	}, fd.Annotations)
+
+	result, err := fd.ResultFn(context.Background(), `{"petId": "1", "mode": "dry", "body": {"name": "Tom"}}`)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, `{"id":1,"name":"Tom"}`, result.Content[0].(mcp.TextContent).Text)
+
+	result, err = fd.ResultFn(context.Background(), `{"petId": "2"}`)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "404 Not Found\nnot found", result.Content[0].(mcp.TextContent).Text)
+
+	result, err = fd.ResultFn(context.Background(), `{}`)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "missing required argument 'petId'", result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestOperationDescription(t *testing.T) {
+	assert.Equal(t, "GET /pets", operationDescription(openapi.Operation{Method: "GET", Path: "/pets"}))
+	assert.Equal(t, "List all pets\n\nReturns all pets of the store.", operationDescription(openapi.Operation{
+		Summary:     "List all pets",
+		Description: "Returns all pets of the store.",
+	}))
+	assert.Equal(t, "Delete a pet\n\nDeprecated: this operation should not be used anymore.", operationDescription(openapi.Operation{
+		Summary:    "Delete a pet",
+		Deprecated: true,
+	}))
+}
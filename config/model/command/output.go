@@ -0,0 +1,215 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"mcp-system-control/mcp/server/builtin/tools/command"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/itchyny/gojq"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	OutputStreamCombined = "combined"
+	OutputStreamStdout   = "stdout"
+	OutputStreamStderr   = "stderr"
+)
+
+type Output struct {
+	Stream     string `yaml:"stream,omitempty" json:"stream,omitempty" usage:"Which output of the command should be used: combined (default), stdout or stderr"`
+	Regex      string `yaml:"regex,omitempty" json:"regex,omitempty" usage:"Regular expression to extract parts of the output. All matches (or their capture groups) will be returned line by line"`
+	Jq         string `yaml:"jq,omitempty" json:"jq,omitempty" usage:"jq-style filter which will be applied to the output (which must be JSON)"`
+	Structured bool   `yaml:"structured,omitempty" json:"structured,omitempty" usage:"Parse the output as JSON object and return it as structured content"`
+	FirstLines int    `yaml:"firstLines,omitempty" json:"firstLines,omitempty" usage:"The number of lines to keep from the beginning of the output"`
+	LastLines  int    `yaml:"lastLines,omitempty" json:"lastLines,omitempty" usage:"The number of lines to keep from the end of the output"`
+	FirstBytes int    `yaml:"firstBytes,omitempty" json:"firstBytes,omitempty" usage:"The number of bytes to keep from the beginning of the output"`
+	LastBytes  int    `yaml:"lastBytes,omitempty" json:"lastBytes,omitempty" usage:"The number of bytes to keep from the end of the output"`
+
+	regex *regexp.Regexp
+	jq    *gojq.Code
+}
+
+// IsSet returns true if any post-processing of the output is configured.
+func (o *Output) IsSet() bool {
+	return o.Stream != "" || o.Regex != "" || o.Jq != "" || o.Structured ||
+		o.FirstLines > 0 || o.LastLines > 0 || o.FirstBytes > 0 || o.LastBytes > 0
+}
+
+// Validate checks and compiles the regular expression and jq filter.
+func (o *Output) Validate() error {
+	switch o.Stream {
+	case "", OutputStreamCombined, OutputStreamStdout, OutputStreamStderr:
+	default:
+		return fmt.Errorf("invalid output stream '%s': must be one of %s, %s or %s", o.Stream, OutputStreamCombined, OutputStreamStdout, OutputStreamStderr)
+	}
+	if o.FirstLines < 0 || o.LastLines < 0 || o.FirstBytes < 0 || o.LastBytes < 0 {
+		return fmt.Errorf("the number of output lines and bytes must not be negative")
+	}
+
+	if o.Regex != "" {
+		var err error
+		if o.regex, err = regexp.Compile(o.Regex); err != nil {
+			return fmt.Errorf("invalid output regex: %w", err)
+		}
+	}
+	if o.Jq != "" {
+		query, err := gojq.Parse(o.Jq)
+		if err != nil {
+			return fmt.Errorf("invalid output jq filter: %w", err)
+		}
+		if o.jq, err = gojq.Compile(query); err != nil {
+			return fmt.Errorf("invalid output jq filter: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// settings returns the output settings for the command execution (stream selection).
+func (o *Output) settings() *command.OutputSettings {
+	if o.Stream == "" || o.Stream == OutputStreamCombined {
+		return nil
+	}
+	return &command.OutputSettings{
+		DisableStdOut: o.Stream == OutputStreamStderr,
+		DisableStdErr: o.Stream == OutputStreamStdout,
+		// the truncation will be done by the post-processing
+		FirstNBytes: -1,
+		LastNBytes:  -1,
+	}
+}
+
+// Process applies the post-processing steps to the given output of the command.
+// The output settings must be validated (see Validate) before.
+func (o *Output) Process(output []byte) (*mcp.CallToolResult, error) {
+	text := string(output)
+
+	if o.Regex != "" {
+		if o.regex == nil {
+			return nil, fmt.Errorf("output regex is not compiled")
+		}
+		text = o.extract(text)
+	}
+
+	var structured any
+	if o.Jq != "" || o.Structured {
+		var value any
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return nil, fmt.Errorf("output is not valid JSON: %w", err)
+		}
+
+		if o.Jq != "" {
+			var err error
+			if value, err = o.filter(value); err != nil {
+				return nil, err
+			}
+			if s, isString := value.(string); isString && !o.Structured {
+				// like "jq -r"
+				text = s
+			} else {
+				raw, err := json.Marshal(value)
+				if err != nil {
+					return nil, fmt.Errorf("unable to serialize output: %w", err)
+				}
+				text = string(raw)
+			}
+		}
+		if o.Structured {
+			if _, isObject := value.(map[string]any); !isObject {
+				return nil, fmt.Errorf("structured output must be a JSON object")
+			}
+			structured = value
+		}
+	}
+
+	text = o.truncate(text)
+
+	if structured != nil {
+		return mcp.NewToolResultStructured(structured, text), nil
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// extract returns all matches of the regex line by line. If the regex contains capture groups,
+// only the groups will be returned (separated by tab).
+func (o *Output) extract(text string) string {
+	var lines []string
+	for _, match := range o.regex.FindAllStringSubmatch(text, -1) {
+		if len(match) == 1 {
+			lines = append(lines, match[0])
+		} else {
+			lines = append(lines, strings.Join(match[1:], "\t"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// filter applies the jq filter to the value. Multiple results will be returned as array.
+func (o *Output) filter(value any) (any, error) {
+	if o.jq == nil {
+		return nil, fmt.Errorf("output jq filter is not compiled")
+	}
+
+	var results []any
+	iter := o.jq.Run(value)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, isErr := v.(error); isErr {
+			return nil, fmt.Errorf("error while applying jq filter: %w", err)
+		}
+		results = append(results, v)
+	}
+
+	switch len(results) {
+	case 0:
+		return nil, nil
+	case 1:
+		return results[0], nil
+	}
+	return results, nil
+}
+
+func (o *Output) truncate(text string) string {
+	if o.FirstLines > 0 || o.LastLines > 0 {
+		// a trailing line break does not start a new line
+		lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+		if len(lines) > o.FirstLines+o.LastLines {
+			var result []string
+			result = append(result, lines[:o.FirstLines]...)
+			result = append(result, fmt.Sprintf("{{ %d lines skipped }}", len(lines)-o.FirstLines-o.LastLines))
+			result = append(result, lines[len(lines)-o.LastLines:]...)
+			text = strings.Join(result, "\n")
+		}
+	}
+
+	if o.FirstBytes > 0 || o.LastBytes > 0 {
+		if len(text) > o.FirstBytes+o.LastBytes {
+			// the cuts must not split a (multibyte) character
+			first := o.FirstBytes
+			for first > 0 && !utf8.RuneStart(text[first]) {
+				first--
+			}
+			last := len(text) - o.LastBytes
+			for last < len(text) && !utf8.RuneStart(text[last]) {
+				last++
+			}
+
+			parts := []string{fmt.Sprintf("{{ %d bytes skipped }}", last-first)}
+			if o.FirstBytes > 0 {
+				parts = append([]string{text[:first]}, parts...)
+			}
+			if o.LastBytes > 0 {
+				parts = append(parts, text[last:])
+			}
+			text = strings.Join(parts, "\n")
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,182 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutput_Process(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     Output
+		input      string
+		expected   string
+		structured any
+	}{
+		{
+			name:     "nothing",
+			input:    "hello\nworld\n",
+			expected: "hello\nworld\n",
+		},
+		{
+			name:     "first lines",
+			output:   Output{FirstLines: 2},
+			input:    "1\n2\n3\n4\n5\n",
+			expected: "1\n2\n{{ 3 lines skipped }}",
+		},
+		{
+			name:     "last lines",
+			output:   Output{LastLines: 2},
+			input:    "1\n2\n3\n4\n5\n",
+			expected: "{{ 3 lines skipped }}\n4\n5",
+		},
+		{
+			name:     "first and last lines",
+			output:   Output{FirstLines: 1, LastLines: 1},
+			input:    "1\n2\n3\n4\n5",
+			expected: "1\n{{ 3 lines skipped }}\n5",
+		},
+		{
+			name:     "lines not exceeded",
+			output:   Output{FirstLines: 3, LastLines: 3},
+			input:    "1\n2\n3\n4\n5\n",
+			expected: "1\n2\n3\n4\n5\n",
+		},
+		{
+			name:     "first and last bytes",
+			output:   Output{FirstBytes: 2, LastBytes: 3},
+			input:    "0123456789",
+			expected: "01\n{{ 5 bytes skipped }}\n789",
+		},
+		{
+			name:     "last bytes",
+			output:   Output{LastBytes: 3},
+			input:    "0123456789",
+			expected: "{{ 7 bytes skipped }}\n789",
+		},
+		{
+			name:     "bytes at character boundaries",
+			output:   Output{FirstBytes: 4, LastBytes: 4},
+			input:    "äöüäöüäöü",
+			expected: "äö\n{{ 10 bytes skipped }}\nöü",
+		},
+		{
+			name:     "bytes inside characters",
+			output:   Output{FirstBytes: 3, LastBytes: 3},
+			input:    "äöüäöüäöü",
+			expected: "ä\n{{ 14 bytes skipped }}\nü",
+		},
+		{
+			name:     "regex",
+			output:   Output{Regex: `version \d+\.\d+`},
+			input:    "app version 1.2\nlib version 3.4\n",
+			expected: "version 1.2\nversion 3.4",
+		},
+		{
+			name:     "regex with groups",
+			output:   Output{Regex: `(\w+) version (\d+\.\d+)`},
+			input:    "app version 1.2\nlib version 3.4\n",
+			expected: "app\t1.2\nlib\t3.4",
+		},
+		{
+			name:     "jq",
+			output:   Output{Jq: `.items[] | select(.ok) | .name`},
+			input:    `{"items": [{"name": "a", "ok": true}, {"name": "b", "ok": false}, {"name": "c", "ok": true}]}`,
+			expected: `["a","c"]`,
+		},
+		{
+			name:     "jq raw string",
+			output:   Output{Jq: `.name`},
+			input:    `{"name": "hello"}`,
+			expected: `hello`,
+		},
+		{
+			name:       "structured",
+			output:     Output{Structured: true},
+			input:      `{"name": "hello"}`,
+			expected:   `{"name": "hello"}`,
+			structured: map[string]any{"name": "hello"},
+		},
+		{
+			name:       "structured with jq",
+			output:     Output{Structured: true, Jq: `{name: .metadata.name}`},
+			input:      `{"metadata": {"name": "hello", "uid": "1"}}`,
+			expected:   `{"name":"hello"}`,
+			structured: map[string]any{"name": "hello"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.output.Validate())
+
+			result, err := tt.output.Process([]byte(tt.input))
+			require.NoError(t, err)
+
+			assert.Equal(t, []mcp.Content{mcp.NewTextContent(tt.expected)}, result.Content)
+			assert.Equal(t, tt.structured, result.StructuredContent)
+		})
+	}
+}
+
+func TestOutput_Process_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   Output
+		input    string
+		expected string
+	}{
+		{"no json", Output{Jq: "."}, "hello", "output is not valid JSON: invalid character 'h' looking for beginning of value"},
+		{"structured no object", Output{Structured: true}, "[1, 2]", "structured output must be a JSON object"},
+		{"jq error", Output{Jq: ".[0]"}, `{"a": 1}`, "error while applying jq filter: expected an array but got: object ({\"a\":1})"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.output.Validate())
+
+			_, err := tt.output.Process([]byte(tt.input))
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestOutput_Process_NotValidated(t *testing.T) {
+	_, err := (&Output{Regex: "."}).Process([]byte("hello"))
+	assert.EqualError(t, err, "output regex is not compiled")
+
+	_, err = (&Output{Jq: "."}).Process([]byte("{}"))
+	assert.EqualError(t, err, "output jq filter is not compiled")
+}
+
+func TestOutput_Validate(t *testing.T) {
+	assert.EqualError(t, (&Output{Stream: "both"}).Validate(), "invalid output stream 'both': must be one of combined, stdout or stderr")
+	assert.EqualError(t, (&Output{FirstLines: -1}).Validate(), "the number of output lines and bytes must not be negative")
+	assert.ErrorContains(t, (&Output{Regex: "("}).Validate(), "invalid output regex")
+	assert.ErrorContains(t, (&Output{Jq: ".["}).Validate(), "invalid output jq filter")
+}
+
+func TestCommand_ResultFn_Stream(t *testing.T) {
+	for _, tc := range []struct {
+		stream   string
+		expected string
+	}{
+		{OutputStreamStdout, "out\n"},
+		{OutputStreamStderr, "err\n"},
+	} {
+		t.Run(tc.stream, func(t *testing.T) {
+			fd := FunctionDefinition{
+				Name:    "test",
+				Command: `sh -c "echo out; echo err >&2"`,
+				Output:  Output{Stream: tc.stream},
+			}
+			require.NoError(t, fd.Output.Validate())
+
+			result, err := Command(fd.Command).ResultFn(fd)(context.Background(), `{}`)
+			require.NoError(t, err)
+			assert.Equal(t, []mcp.Content{mcp.NewTextContent(tc.expected)}, result.Content)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mvdan.cc/sh/v3/expand"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// refPrefix is the prefix of the (shell compatible) variable names which replace the extended references.
+const refPrefix = "__mcp_ref_"
+
+// refPattern matches the references which are not supported by the shell syntax itself:
+//   - nested access: ${config.dir}
+//   - array expansion: ${files[@]}
+//   - conditional flags: ${force:+--force}
+var refPattern = regexp.MustCompile(`\$\{([#!]?)([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z0-9_]+)*)(\[[@*]\])?(:?\+)?`)
+
+type reference struct {
+	path []string
+	// the value is accessed as array (each element will be an own entry)
+	array bool
+	// the value is used as condition: false will be treated as unset
+	condition bool
+}
+
+// placeholders resolves the placeholders of a tool's command, environment and working directory
+// by the arguments of the LLM.
+type placeholders struct {
+	jsonArgs string
+	args     map[string]any
+	refs     []reference
+	// escape (if set) converts each value into its (escaped) string (e.g. for the usage inside an url)
+	escape func(value any) (string, error)
+}
+
+func newPlaceholders(jsonArgs string) (*placeholders, error) {
+	p := &placeholders{jsonArgs: jsonArgs}
+	if err := json.Unmarshal([]byte(jsonArgs), &p.args); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return p, nil
+}
+
+// encode replaces all extended references by shell compatible variable names.
+func (p *placeholders) encode(s string) string {
+	return refPattern.ReplaceAllStringFunc(s, func(match string) string {
+		m := refPattern.FindStringSubmatch(match)
+		ref := reference{
+			path:      strings.Split(m[2], "."),
+			array:     m[3] != "",
+			condition: m[4] != "",
+		}
+		if len(ref.path) == 1 && !ref.array && !ref.condition {
+			return match
+		}
+
+		p.refs = append(p.refs, ref)
+		return "${" + m[1] + refPrefix + strconv.Itoa(len(p.refs)-1) + m[3] + m[4]
+	})
+}
+
+// Fields expands the given command into its fields (like a shell would do).
+func (p *placeholders) Fields(s string) ([]string, error) {
+	parser := syntax.NewParser()
+
+	var words []*syntax.Word
+	for w, err := range parser.WordsSeq(strings.NewReader(p.encode(s))) {
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, w)
+	}
+
+	fields, err := expand.Fields(p.config(), words...)
+	return fields, p.decodeError(err)
+}
+
+// Document expands the given value as one string (no field splitting and quote removal).
+func (p *placeholders) Document(s string) (string, error) {
+	word, err := syntax.NewParser().Document(strings.NewReader(p.encode(s)))
+	if err != nil {
+		return "", err
+	}
+
+	result, err := expand.Document(p.config(), word)
+	return result, p.decodeError(err)
+}
+
+// DocumentEscaped expands the given value like Document does, but escapes each inserted value.
+func (p *placeholders) DocumentEscaped(s string, escape func(value any) (string, error)) (string, error) {
+	p.escape = escape
+	defer func() { p.escape = nil }()
+
+	return p.Document(s)
+}
+
+func (p *placeholders) config() *expand.Config {
+	return &expand.Config{Env: p}
+}
+
+// decodeError replaces the encoded variable names with the original references.
+func (p *placeholders) decodeError(err error) error {
+	var ue expand.UnsetParameterError
+	if errors.As(err, &ue) {
+		name := ue.Node.Param.Value
+		if ref, found := p.reference(name); found {
+			name = strings.Join(ref.path, ".")
+		}
+		message := ue.Message
+		if message == "" || message == "parameter null or not set" || message == "parameter not set" {
+			return fmt.Errorf("missing required argument '%s'", name)
+		}
+		return fmt.Errorf("missing required argument '%s': %s", name, message)
+	}
+	return err
+}
+
+func (p *placeholders) reference(name string) (reference, bool) {
+	if !strings.HasPrefix(name, refPrefix) {
+		return reference{}, false
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(name, refPrefix))
+	if err != nil || i < 0 || i >= len(p.refs) {
+		return reference{}, false
+	}
+	return p.refs[i], true
+}
+
+// Get implements expand.Environ
+func (p *placeholders) Get(name string) expand.Variable {
+	if name == FunctionArgumentNameAll {
+		return expand.Variable{Set: true, Kind: expand.String, Str: p.jsonArgs}
+	}
+
+	ref, found := p.reference(name)
+	if !found {
+		ref = reference{path: []string{name}}
+	}
+
+	value, exists := lookup(p.args, ref.path)
+	if !exists || value == nil {
+		if ref.array {
+			// expands to no field at all (instead of one empty field)
+			return expand.Variable{Set: true, Kind: expand.Indexed, List: []string{}}
+		}
+		return expand.Variable{}
+	}
+	if b, isBool := value.(bool); isBool && !b && ref.condition {
+		return expand.Variable{}
+	}
+
+	if list, isList := value.([]any); isList && ref.array {
+		vr := expand.Variable{Set: true, Kind: expand.Indexed, List: []string{}}
+		for _, e := range list {
+			s, err := p.toString(e)
+			if err != nil {
+				return expand.Variable{}
+			}
+			vr.List = append(vr.List, s)
+		}
+		return vr
+	}
+
+	s, err := p.toString(value)
+	if err != nil {
+		return expand.Variable{}
+	}
+	return expand.Variable{Set: true, Kind: expand.String, Str: s}
+}
+
+func (p *placeholders) toString(value any) (string, error) {
+	if p.escape != nil {
+		return p.escape(value)
+	}
+	return toString(value)
+}
+
+// Each implements expand.Environ
+func (p *placeholders) Each(func(name string, vr expand.Variable) bool) {}
+
+func lookup(value any, path []string) (any, bool) {
+	for _, key := range path {
+		switch v := value.(type) {
+		case map[string]any:
+			var exists bool
+			if value, exists = v[key]; !exists {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// toString converts the value into a string. Strings are used as they are, all other values as JSON.
+func toString(value any) (string, error) {
+	if s, isString := value.(string); isString {
+		return s, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
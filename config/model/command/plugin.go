@@ -0,0 +1,120 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mcp-system-control/approval"
+	"mcp-system-control/expression"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	pluginKindJS   = "plugin"
+	pluginKindWasm = "wasm plugin"
+)
+
+// approvalTypes describes the supported approvals of each plugin kind.
+var approvalTypes = map[string]string{
+	pluginKindJS:   "a function, boolean or expression",
+	pluginKindWasm: "a boolean or expression",
+}
+
+// PluginDefinition converts the tool of a JS plugin into a function definition.
+func PluginDefinition(tool expression.PluginTool) (FunctionDefinition, error) {
+	var toolApproval any
+	if !tool.ApprovalFn {
+		toolApproval = tool.Approval
+	}
+
+	fd, err := toolDefinition(tool.Name, tool.Description, tool.InputSchema, tool.Annotations, toolApproval, pluginKindJS)
+	if err != nil {
+		return fd, err
+	}
+
+	if tool.ApprovalFn {
+		fd.ApprovalFn = func(ctx context.Context, jsonArguments string) bool {
+			b, err := tool.CallApproval(ctx, pluginVariables(fd, jsonArguments)).AsBoolean()
+			if err != nil {
+				slog.Error("error running approval function of plugin", "tool", tool.Name, "error", err)
+				return true
+			}
+			return b
+		}
+	}
+
+	fd.ResultFn = func(ctx context.Context, jsonArguments string) (*mcp.CallToolResult, error) {
+		result, err := tool.Handle(ctx, pluginVariables(fd, jsonArguments)).AsToolResult()
+		if err != nil {
+			return nil, fmt.Errorf("error running plugin: %w", err)
+		}
+		return result, nil
+	}
+
+	return fd, nil
+}
+
+// toolDefinition creates the function definition (without result function) of a plugin tool. The
+// approval can be a boolean or an approval expression. The kind of the plugin is used for the error messages.
+func toolDefinition(name, description string, schema, annotations map[string]any, toolApproval any, kind string) (FunctionDefinition, error) {
+	fd := FunctionDefinition{
+		Name:        name,
+		Description: description,
+	}
+
+	if schema != nil {
+		if err := convert(schema, &fd.Parameters); err != nil {
+			return fd, fmt.Errorf("invalid input schema of %s tool '%s': %w", kind, name, err)
+		}
+	}
+	if fd.Parameters.Type == "" {
+		fd.Parameters.Type = "object"
+	}
+	if fd.Parameters.Properties == nil {
+		fd.Parameters.Properties = make(map[string]any)
+	}
+	if annotations != nil {
+		if err := convert(annotations, &fd.Annotations); err != nil {
+			return fd, fmt.Errorf("invalid annotations of %s tool '%s': %w", kind, name, err)
+		}
+	}
+
+	switch v := toolApproval.(type) {
+	case nil:
+	case bool:
+		fd.Approval = approval.Never
+		if v {
+			fd.Approval = approval.Always
+		}
+	case string:
+		if err := approval.Approval(v).Validate(); err != nil {
+			return fd, fmt.Errorf("invalid approval of %s tool '%s': %w", kind, name, err)
+		}
+		fd.Approval = v
+	default:
+		return fd, fmt.Errorf("invalid approval of %s tool '%s': must be %s", kind, name, approvalTypes[kind])
+	}
+
+	return fd, nil
+}
+
+func pluginVariables(fd FunctionDefinition, jsonArguments string) approval.Variables {
+	vars := approval.Variables{
+		ToolDefinition: fd,
+		RawArguments:   jsonArguments,
+	}
+	if err := json.Unmarshal([]byte(jsonArguments), &vars.ParsedArguments); err != nil {
+		slog.Warn("error parsing arguments", "args", jsonArguments, "error", err)
+	}
+	return vars
+}
+
+func convert(source any, target any) error {
+	raw, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, target)
+}
@@ -0,0 +1,91 @@
+package command
+
+import (
+	"context"
+	"mcp-system-control/expression"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginDefinition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugin.js")
+	require.NoError(t, os.WriteFile(path, []byte(`
+module.exports = {
+	name: "greet",
+	description: "Greets somebody.",
+	inputSchema: { type: "object", properties: { name: { type: "string" } }, required: ["name"] },
+	annotations: { title: "Greeting", readOnlyHint: true },
+	approval: (ctx) => ctx.args.name === "root",
+	handler: (ctx) => ({ content: ["Hello " + ctx.args.name + " from " + ctx.definition.name] }),
+}
+`), 0644))
+
+	tools, err := expression.LoadPlugin(path)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	fd, err := PluginDefinition(tools[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, "greet", fd.Name)
+	assert.Equal(t, "Greets somebody.", fd.Description)
+	assert.Equal(t, mcp.ToolInputSchema{
+		Type: "object",
+		Properties: map[string]any{
+			"name": map[string]any{"type": "string"},
+		},
+		Required: []string{"name"},
+	}, fd.Parameters)
+	assert.Equal(t, mcp.ToolAnnotation{Title: "Greeting", ReadOnlyHint: mcp.ToBoolPtr(true)}, fd.Annotations)
+
+	assert.True(t, fd.NeedApproval(context.Background(), `{"name": "root"}`))
+	assert.False(t, fd.NeedApproval(context.Background(), `{"name": "World"}`))
+
+	result, err := fd.ResultFn(context.Background(), `{"name": "World"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World from greet", result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestPluginDefinition_StaticApproval(t *testing.T) {
+	tests := []struct {
+		approval any
+		expected bool
+		err      string
+	}{
+		{approval: nil, expected: false},
+		{approval: true, expected: true},
+		{approval: false, expected: false},
+		{approval: "cel: args.name == 'root'", expected: true},
+		{approval: "cel: args.name ==", err: "invalid approval of plugin tool 'test'"},
+		{approval: 1, err: "must be a function, boolean or expression"},
+	}
+	for _, tt := range tests {
+		fd, err := PluginDefinition(expression.PluginTool{Name: "test", Approval: tt.approval})
+		if tt.err != "" {
+			assert.ErrorContains(t, err, tt.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, fd.NeedApproval(context.Background(), `{"name": "root"}`), "approval: %v", tt.approval)
+	}
+}
+
+func Test_toolDefinition(t *testing.T) {
+	fd, err := toolDefinition("test", "A test.", nil, map[string]any{"readOnlyHint": true}, "cel: true", pluginKindWasm)
+	require.NoError(t, err)
+	assert.Equal(t, "object", fd.Parameters.Type)
+	assert.NotNil(t, fd.Parameters.Properties)
+	assert.True(t, *fd.Annotations.ReadOnlyHint)
+	assert.Equal(t, "cel: true", fd.Approval)
+
+	_, err = toolDefinition("test", "", nil, nil, 1, pluginKindWasm)
+	assert.EqualError(t, err, "invalid approval of wasm plugin tool 'test': must be a boolean or expression")
+
+	_, err = toolDefinition("test", "", map[string]any{"type": 1}, nil, nil, pluginKindJS)
+	assert.ErrorContains(t, err, "invalid input schema of plugin tool 'test'")
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const schemaResource = "parameters.json"
+
+// CompileSchema compiles the parameter definition of the function. An error will be returned if the
+// parameter definition is not a valid JSON schema.
+func (f *FunctionDefinition) CompileSchema() error {
+	raw, err := json.Marshal(f.Parameters)
+	if err != nil {
+		return fmt.Errorf("unable to serialize parameters: %w", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("unable to parse parameters: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err = compiler.AddResource(schemaResource, doc); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	schema, err := compiler.Compile(schemaResource)
+	if err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	f.schema = schema
+	return nil
+}
+
+// ValidateArguments validates the given (json) arguments against the parameter definition of the function.
+// The schema must be compiled before (see CompileSchema); otherwise the arguments will not be validated.
+func (f *FunctionDefinition) ValidateArguments(jsonArgs string) error {
+	if f.schema == nil {
+		return nil
+	}
+
+	args, err := jsonschema.UnmarshalJSON(strings.NewReader(jsonArgs))
+	if err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args == nil {
+		// no arguments are the same as empty arguments
+		args = map[string]any{}
+	}
+
+	err = f.schema.Validate(args)
+
+	var ve *jsonschema.ValidationError
+	if errors.As(err, &ve) {
+		return fmt.Errorf("invalid arguments:\n%s", strings.Join(violationsOf(ve.BasicOutput()), "\n"))
+	}
+	return err
+}
+
+func violationsOf(unit *jsonschema.OutputUnit) []string {
+	var result []string
+	for _, e := range unit.Errors {
+		if e.Error == nil {
+			continue
+		}
+		location := e.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		result = append(result, fmt.Sprintf("- at '%s': %s", location, e.Error.String()))
+	}
+	if len(result) == 0 && unit.Error != nil {
+		result = append(result, "- "+unit.Error.String())
+	}
+	return result
+}
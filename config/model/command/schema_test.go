@@ -0,0 +1,72 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func schemaDefinition(t *testing.T) FunctionDefinition {
+	fd := FunctionDefinition{
+		Parameters: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]any{
+				"path":  map[string]any{"type": "string", "pattern": "^/"},
+				"mode":  map[string]any{"type": "string", "enum": []any{"fast", "slow"}},
+				"count": map[string]any{"type": "integer", "minimum": 1, "maximum": 10},
+			},
+			Required: []string{"path"},
+		},
+	}
+	require.NoError(t, fd.CompileSchema())
+	return fd
+}
+
+func TestFunctionDefinition_ValidateArguments(t *testing.T) {
+	fd := schemaDefinition(t)
+
+	tests := []struct {
+		name     string
+		args     string
+		expected string
+	}{
+		{"valid", `{"path": "/tmp", "mode": "fast", "count": 3}`, ""},
+		{"missing required", `{}`, "invalid arguments:\n- at '/': missing property 'path'"},
+		{"null arguments", `null`, "invalid arguments:\n- at '/': missing property 'path'"},
+		{"wrong type", `{"path": 1}`, "invalid arguments:\n- at '/path': got number, want string"},
+		{"pattern", `{"path": "tmp"}`, "invalid arguments:\n- at '/path': 'tmp' does not match pattern '^/'"},
+		{"enum", `{"path": "/tmp", "mode": "medium"}`, "invalid arguments:\n- at '/mode': value must be one of 'fast', 'slow'"},
+		{"maximum", `{"path": "/tmp", "count": 11}`, "invalid arguments:\n- at '/count': maximum: got 11, want 10"},
+		{"not an integer", `{"path": "/tmp", "count": 1.5}`, "invalid arguments:\n- at '/count': got number, want integer"},
+		{"invalid json", `{`, "invalid arguments: unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fd.ValidateArguments(tt.args)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFunctionDefinition_ValidateArguments_NotCompiled(t *testing.T) {
+	fd := FunctionDefinition{Parameters: mcp.ToolInputSchema{Type: "object", Required: []string{"path"}}}
+
+	assert.NoError(t, fd.ValidateArguments(`{}`))
+}
+
+func TestFunctionDefinition_CompileSchema_Invalid(t *testing.T) {
+	fd := FunctionDefinition{
+		Parameters: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]any{"path": map[string]any{"type": "text"}},
+		},
+	}
+
+	assert.ErrorContains(t, fd.CompileSchema(), "invalid parameters")
+}
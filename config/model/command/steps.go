@@ -0,0 +1,48 @@
+package command
+
+import (
+	"fmt"
+
+	"mcp-system-control/mcp/server/builtin/tools/command"
+)
+
+type Step struct {
+	Command         string `yaml:"command,omitempty" json:"command" usage:"The command of the step. This is a format string with placeholders for the parameters. Example: grep -rn $pattern"`
+	ContinueOnError bool   `yaml:"continueOnError,omitempty" json:"continueOnError,omitempty" usage:"Ignore an unsuccessful exit code of this step. Otherwise the tool call will fail"`
+}
+
+type Steps []Step
+
+func (s Steps) Validate() error {
+	for i, step := range s {
+		if ve := Command(step.Command).Validate(); ve != nil {
+			return fmt.Errorf("invalid step #%d: %w", i+1, ve)
+		}
+	}
+
+	return nil
+}
+
+func (f *FunctionDefinition) GetSteps(jsonArgs string) ([]command.CommandStep, error) {
+	p, err := newPlaceholders(jsonArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]command.CommandStep, len(f.Steps))
+	for i, step := range f.Steps {
+		fields, err := p.Fields(step.Command)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse command of step #%d: %w", i+1, err)
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty command in step #%d", i+1)
+		}
+		result[i] = command.CommandStep{
+			Name:            fields[0],
+			Arguments:       fields[1:],
+			ContinueOnError: step.ContinueOnError,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"mcp-system-control/mcp/server/builtin/tools/command"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionDefinition_GetSteps(t *testing.T) {
+	fd := FunctionDefinition{
+		Steps: Steps{
+			{Command: `find "$dir" -name "*.go"`},
+			{Command: `xargs grep -n "${pattern:?}"`, ContinueOnError: true},
+			{Command: `head -n ${limit:-10}`},
+		},
+	}
+
+	steps, err := fd.GetSteps(`{"dir": "/tmp/my dir", "pattern": "func main"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []command.CommandStep{
+		{Name: "find", Arguments: []string{"/tmp/my dir", "-name", "*.go"}},
+		{Name: "xargs", Arguments: []string{"grep", "-n", "func main"}, ContinueOnError: true},
+		{Name: "head", Arguments: []string{"-n", "10"}},
+	}, steps)
+
+	_, err = fd.GetSteps(`{"dir": "/tmp"}`)
+	assert.EqualError(t, err, "failed to parse command of step #2: missing required argument 'pattern'")
+
+	fd.Steps = Steps{{Command: `$empty`}}
+	_, err = fd.GetSteps(`{}`)
+	assert.EqualError(t, err, "empty command in step #1")
+}
+
+func TestSteps_Validate(t *testing.T) {
+	assert.NoError(t, Steps{{Command: "echo"}}.Validate())
+	assert.EqualError(t, Steps{{Command: "echo"}, {}}.Validate(), "invalid step #2: empty command")
+}
+
+func TestCommand_ResultFn_Steps(t *testing.T) {
+	fd := FunctionDefinition{
+		Name: "test",
+		Steps: Steps{
+			{Command: `printf "b\na\nb\n"`},
+			{Command: `grep -c $pattern`},
+		},
+	}
+
+	result, err := Command(fd.Command).ResultFn(fd)(context.Background(), `{"pattern": "b"}`)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("2\n")}, result.Content)
+
+	result, err = Command(fd.Command).ResultFn(fd)(context.Background(), `{"pattern": "c"}`)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("step #2 (grep) failed: command exited with code 1")}, result.Content)
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp-system-control/expression"
+	"mcp-system-control/wasm"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WasmPluginDefinition converts the tool of a wasm plugin into a function definition.
+func WasmPluginDefinition(tool wasm.Tool) (FunctionDefinition, error) {
+	fd, err := toolDefinition(tool.Name, tool.Description, tool.InputSchema, tool.Annotations, tool.Approval, pluginKindWasm)
+	if err != nil {
+		return fd, err
+	}
+
+	fd.ResultFn = func(ctx context.Context, jsonArguments string) (*mcp.CallToolResult, error) {
+		result, err := tool.Call(ctx, jsonArguments)
+		if err != nil {
+			return nil, err
+		}
+		return wasmToolResult(result)
+	}
+
+	return fd, nil
+}
+
+// wasmToolResult interprets the plugin's result like the result of an expression: objects with
+// content/structuredContent/isError are tool results, strings are text and everything else is
+// returned as JSON text.
+func wasmToolResult(result any) (*mcp.CallToolResult, error) {
+	switch v := result.(type) {
+	case string:
+		return mcp.NewToolResultText(v), nil
+	case map[string]any:
+		r, err := expression.ToolResultFromMap(v)
+		if err != nil || r != nil {
+			return r, err
+		}
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize result of wasm plugin: %w", err)
+	}
+	return mcp.NewToolResultText(string(raw)), nil
+}
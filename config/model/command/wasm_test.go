@@ -0,0 +1,38 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWasmToolResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   any
+		expected *mcp.CallToolResult
+	}{
+		{"string", "Hello", mcp.NewToolResultText("Hello")},
+		{"number", float64(42), mcp.NewToolResultText("42")},
+		{"plain object", map[string]any{"a": "b"}, mcp.NewToolResultText(`{"a":"b"}`)},
+		{"tool result", map[string]any{"content": []any{"Hello"}, "isError": true}, &mcp.CallToolResult{
+			Content: []mcp.Content{mcp.NewTextContent("Hello")},
+			IsError: true,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := wasmToolResult(tt.result)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.Content, result.Content)
+			assert.Equal(t, tt.expected.IsError, result.IsError)
+		})
+	}
+}
+
+func TestWasmToolResult_Invalid(t *testing.T) {
+	_, err := wasmToolResult(map[string]any{"content": "no array"})
+	assert.EqualError(t, err, "content must be an array")
+}
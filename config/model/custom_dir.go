@@ -0,0 +1,63 @@
+package model
+
+import (
+	"fmt"
+	"mcp-system-control/config/model/command"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+type CustomDir struct {
+	Path           string        `yaml:"path,omitempty" usage:"Directory with tool definition files (*.yaml, *.yml, *.json). Each file contains one tool definition. The file name (without extension) is the name of the tool"`
+	DisableReload  bool          `yaml:"disableReload,omitempty" usage:"Disable the live reload of the tool definition files"`
+	ReloadInterval time.Duration `yaml:"reloadInterval,omitempty" usage:"Interval in which the directory will be checked for changes"`
+}
+
+func (c *CustomDir) SetDefaults() {
+	if c.ReloadInterval == 0 {
+		c.ReloadInterval = 2 * time.Second
+	}
+}
+
+func (c *CustomDir) Validate() error {
+	if c.ReloadInterval <= 0 {
+		return fmt.Errorf("invalid reload interval for custom tool directory: %s", c.ReloadInterval)
+	}
+	return nil
+}
+
+// IsCustomFile checks if the given file name is a tool definition file.
+func IsCustomFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// CustomToolName returns the name of the tool which is defined by the given file.
+func CustomToolName(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// LoadCustomFile reads the tool definition of the given file (YAML or JSON) and prepares it (see PrepareCustom).
+func LoadCustomFile(path string) (command.FunctionDefinition, error) {
+	var definition command.FunctionDefinition
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return definition, fmt.Errorf("unable to read tool definition file '%s': %w", path, err)
+	}
+
+	// JSON is a subset of YAML, so both can be parsed the same way
+	if err = yaml.Unmarshal(raw, &definition); err != nil {
+		return definition, fmt.Errorf("unable to parse tool definition file '%s': %w", path, err)
+	}
+
+	return PrepareCustom(CustomToolName(path), definition)
+}
@@ -0,0 +1,14 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomDir_Validate(t *testing.T) {
+	assert.EqualError(t, (&CustomDir{ReloadInterval: -time.Second}).Validate(), "invalid reload interval for custom tool directory: -1s")
+	assert.EqualError(t, (&CustomDir{}).Validate(), "invalid reload interval for custom tool directory: 0s")
+	assert.NoError(t, (&CustomDir{Path: "/tmp", ReloadInterval: time.Second}).Validate())
+}
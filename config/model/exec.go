@@ -2,11 +2,17 @@ package model
 
 import (
 	"mcp-system-control/approval"
+	"mcp-system-control/mcp/server/builtin/tools/command"
+	"time"
 )
 
 type CommandExecution struct {
-	Disable  bool   `yaml:"disable,omitempty" usage:"disable"`
-	Approval string `yaml:"approval,omitempty" usage:"Expression to check if user approval is needed before execute this tool"`
+	Disable          bool          `yaml:"disable,omitempty" usage:"disable"`
+	Approval         string        `yaml:"approval,omitempty" usage:"Expression to check if user approval is needed before execute this tool"`
+	Timeout          time.Duration `yaml:"timeout,omitempty" usage:"The maximum execution time of a command. After that, the command will be terminated (SIGTERM)"`
+	GracePeriod      time.Duration `yaml:"gracePeriod,omitempty" usage:"The time between the termination (SIGTERM) and the kill (SIGKILL) of a timed out command (default: 5s)"`
+	SuccessExitCodes []int         `yaml:"successExitCodes,omitempty" usage:"The exit codes which are treated as success (default: 0). Only relevant in combination with failOnExitCode"`
+	FailOnExitCode   bool          `yaml:"failOnExitCode,omitempty" usage:"Report an unsuccessful exit code as error result (including the exit code and the end of stderr)"`
 }
 
 func (c *CommandExecution) SetDefaults() {
@@ -14,3 +20,13 @@ func (c *CommandExecution) SetDefaults() {
 		c.Approval = approval.Always
 	}
 }
+
+// Settings returns the timeout and exit code settings for the executed commands.
+func (c *CommandExecution) Settings() command.CommandDescriptor {
+	return command.CommandDescriptor{
+		TimeoutMs:        c.Timeout.Milliseconds(),
+		GracePeriodMs:    c.GracePeriod.Milliseconds(),
+		SuccessExitCodes: c.SuccessExitCodes,
+		FailOnExitCode:   c.FailOnExitCode,
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"mcp-system-control/expression"
+	"os"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+type Expression struct {
+	DisableReload  bool          `yaml:"disableReload,omitempty" usage:"Disable the hot-reload of expression files"`
+	ReloadInterval time.Duration `yaml:"reloadInterval,omitempty" usage:"Interval in which expression files will be checked for changes"`
+
+	Globals     map[string]any    `yaml:"globals,omitempty" usage:"Global (read-only) variables which are available in all expressions"`
+	GlobalFiles map[string]string `yaml:"globalFiles,omitempty" usage:"Global variables which are available in all expressions. The values will be loaded from the given JSON/YAML files"`
+	InitScripts []string          `yaml:"init,omitempty" usage:"Scripts (or paths to script files) which will be executed before each expression. Can be used to define shared helper functions"`
+}
+
+func (e *Expression) SetDefaults() {
+	if e.ReloadInterval == 0 {
+		e.ReloadInterval = 2 * time.Second
+	}
+}
+
+func (e *Expression) Validate() error {
+	if e.ReloadInterval <= 0 {
+		return fmt.Errorf("invalid reload interval for expression files: %s", e.ReloadInterval)
+	}
+
+	globals := map[string]any{}
+	for name, value := range e.Globals {
+		globals[name] = value
+	}
+	for name, path := range e.GlobalFiles {
+		if _, exists := globals[name]; exists {
+			return fmt.Errorf("global expression variable '%s' is defined multiple times", name)
+		}
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read file for global expression variable '%s': %w", name, err)
+		}
+
+		var value any
+		if err = yaml.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("unable to parse file for global expression variable '%s': %w", name, err)
+		}
+		globals[name] = value
+	}
+
+	if err := expression.SetGlobalVariables(globals); err != nil {
+		return err
+	}
+	if err := expression.SetInitScripts(e.InitScripts); err != nil {
+		return err
+	}
+
+	return nil
+}
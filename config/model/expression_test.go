@@ -0,0 +1,14 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpression_Validate_ReloadInterval(t *testing.T) {
+	assert.EqualError(t, (&Expression{ReloadInterval: -time.Second}).Validate(), "invalid reload interval for expression files: -1s")
+	assert.EqualError(t, (&Expression{}).Validate(), "invalid reload interval for expression files: 0s")
+	assert.NoError(t, (&Expression{ReloadInterval: time.Second}).Validate())
+}
@@ -2,8 +2,10 @@ package model
 
 import (
 	"fmt"
-	"mcp-system-control/config/model/approval"
+	"mcp-system-control/approval"
+	mApproval "mcp-system-control/config/model/approval"
 	"mcp-system-control/config/model/command"
+	"sort"
 )
 
 type Config struct {
@@ -13,13 +15,23 @@ type Config struct {
 
 	MCP MCP `yaml:"mcp,omitempty" usage:"MCP server: "`
 
-	Approval approval.Approval `yaml:"approval,omitempty" usage:"Approval "`
+	Approval mApproval.Approval `yaml:"approval,omitempty" usage:"Approval "`
+
+	Expression Expression `yaml:"expression,omitempty" usage:"Expression: "`
 
 	BuiltIns BuiltIns                              `yaml:"builtin,omitempty" usage:"Built-in tool "`
 	Custom   map[string]command.FunctionDefinition `yaml:"custom,omitempty" usage:"Custom tool definition "`
+	Plugins  Plugins                               `yaml:"plugins,omitempty" usage:"Plugins: "`
+
+	CustomDir CustomDir          `yaml:"custom-dir,omitempty" usage:"Custom tool directory: "`
+	OpenAPI   map[string]OpenAPI `yaml:"openapi,omitempty" usage:"OpenAPI tools "`
+
+	ReadOnly bool `yaml:"read-only,omitempty" usage:"Read-only mode: only the tools which are annotated as read-only (readOnlyHint) will be provided"`
 
 	Version bool `yaml:"version,omitempty" short:"v" usage:"Show the version"`
 
+	TestExpression TestExpression `yaml:",inline,omitempty"`
+
 	Help Help `yaml:",inline,omitempty"`
 }
 
@@ -31,32 +43,140 @@ func (c *Config) Validate() error {
 	if ve := c.DebugConfig.Validate(); ve != nil {
 		return ve
 	}
+	if ve := c.Expression.Validate(); ve != nil {
+		return ve
+	}
+	if ve := c.CustomDir.Validate(); ve != nil {
+		return ve
+	}
+	if ve := approval.Approval(c.Approval.ReadOnlyTools).Validate(); ve != nil {
+		return fmt.Errorf("invalid approval expression for read-only tools: %w", ve)
+	}
+	if ve := approval.Approval(c.Approval.DestructiveTools).Validate(); ve != nil {
+		return fmt.Errorf("invalid approval expression for destructive tools: %w", ve)
+	}
+	if ve := c.BuiltIns.Validate(); ve != nil {
+		return ve
+	}
+	policy := c.ToolPolicy()
+	c.BuiltIns.ApplyPolicy(policy)
 
 	for cmd, definition := range c.Custom {
-		definition.Name = cmd
+		definition, err := PrepareCustom(cmd, definition)
+		if err != nil {
+			return err
+		}
 
-		if definition.Parameters.Type == "" && len(definition.Parameters.Properties) == 0 {
-			definition.Parameters.Type = "object"                   // Default to object if no type is set
-			definition.Parameters.Properties = make(map[string]any) // Ensure Properties is initialized
+		// definition is only a local copy, so we need to set it back
+		c.Custom[cmd] = definition
+	}
+
+	plugins, err := c.Plugins.Load()
+	if err != nil {
+		return err
+	}
+	for _, definition := range plugins {
+		if _, exists := c.Custom[definition.Name]; exists {
+			return fmt.Errorf("plugin tool '%s' is already defined", definition.Name)
+		}
+		if ve := definition.CompileSchema(); ve != nil {
+			return fmt.Errorf("invalid parameters for plugin tool '%s': %w", definition.Name, ve)
 		}
+		if c.Custom == nil {
+			c.Custom = map[string]command.FunctionDefinition{}
+		}
+		c.Custom[definition.Name] = definition
+	}
+
+	names := make([]string, 0, len(c.OpenAPI))
+	for name := range c.OpenAPI {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-		if definition.CommandExpr != "" {
-			if ve := command.Expression(definition.CommandExpr).Validate(); ve != nil {
-				return ve
+	for _, name := range names {
+		api := c.OpenAPI[name]
+		definitions, err := api.Load(name)
+		if err != nil {
+			return err
+		}
+		for _, definition := range definitions {
+			if _, exists := c.Custom[definition.Name]; exists {
+				return fmt.Errorf("tool '%s' of OpenAPI '%s' is already defined", definition.Name, name)
 			}
-			definition.CommandFn = command.Expression(definition.CommandExpr).CommandFn(definition)
-		} else if definition.Command != "" {
-			if ve := command.Command(definition.Command).Validate(); ve != nil {
-				return ve
+			if ve := definition.CompileSchema(); ve != nil {
+				return fmt.Errorf("invalid parameters for tool '%s' of OpenAPI '%s': %w", definition.Name, name, ve)
 			}
-			definition.CommandFn = command.Command(definition.Command).CommandFn(definition)
-		} else {
-			return fmt.Errorf("Command for tool '%s' is missing", cmd)
+			if c.Custom == nil {
+				c.Custom = map[string]command.FunctionDefinition{}
+			}
+			c.Custom[definition.Name] = definition
 		}
+	}
 
-		// definition is only a local copy, so we need to set it back
-		c.Custom[cmd] = definition
+	for name, definition := range c.Custom {
+		if definition, allowed := policy.Apply(definition); allowed {
+			c.Custom[name] = definition
+		} else {
+			delete(c.Custom, name)
+		}
 	}
 
 	return nil
 }
+
+// PrepareCustom validates the given custom tool definition and creates its command functions.
+func PrepareCustom(cmd string, definition command.FunctionDefinition) (command.FunctionDefinition, error) {
+	definition.Name = cmd
+
+	if definition.Parameters.Type == "" && len(definition.Parameters.Properties) == 0 {
+		definition.Parameters.Type = "object"                   // Default to object if no type is set
+		definition.Parameters.Properties = make(map[string]any) // Ensure Properties is initialized
+	}
+
+	if definition.HTTP.IsSet() {
+		if definition.CommandExpr != "" || definition.Command != "" || len(definition.Steps) > 0 {
+			return definition, fmt.Errorf("tool '%s' must not define http and a command at the same time", cmd)
+		}
+		if ve := definition.HTTP.Validate(); ve != nil {
+			return definition, fmt.Errorf("invalid http call for tool '%s': %w", cmd, ve)
+		}
+		if ve := definition.Output.Validate(); ve != nil {
+			return definition, fmt.Errorf("invalid output for tool '%s': %w", cmd, ve)
+		}
+		definition.ResultFn = command.HTTPResultFn(definition)
+	} else if definition.CommandExpr != "" {
+		if ve := command.Expression(definition.CommandExpr).Validate(); ve != nil {
+			return definition, ve
+		}
+		definition.CommandFn = command.Expression(definition.CommandExpr).CommandFn(definition)
+		definition.ResultFn = command.Expression(definition.CommandExpr).ResultFn(definition)
+	} else if definition.Command != "" || len(definition.Steps) > 0 {
+		if definition.Command != "" && len(definition.Steps) > 0 {
+			return definition, fmt.Errorf("tool '%s' must not define command and steps at the same time", cmd)
+		}
+		if len(definition.Steps) > 0 {
+			if ve := definition.Steps.Validate(); ve != nil {
+				return definition, fmt.Errorf("invalid steps for tool '%s': %w", cmd, ve)
+			}
+		} else if ve := command.Command(definition.Command).Validate(); ve != nil {
+			return definition, ve
+		}
+		if ve := definition.Output.Validate(); ve != nil {
+			return definition, fmt.Errorf("invalid output for tool '%s': %w", cmd, ve)
+		}
+		definition.CommandFn = command.Command(definition.Command).CommandFn(definition)
+		definition.ResultFn = command.Command(definition.Command).ResultFn(definition)
+	} else {
+		return definition, fmt.Errorf("Command for tool '%s' is missing", cmd)
+	}
+
+	if ve := approval.Approval(definition.Approval).Validate(); ve != nil {
+		return definition, fmt.Errorf("invalid approval expression for tool '%s': %w", cmd, ve)
+	}
+	if ve := definition.CompileSchema(); ve != nil {
+		return definition, fmt.Errorf("invalid parameters for tool '%s': %w", cmd, ve)
+	}
+
+	return definition, nil
+}
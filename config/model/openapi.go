@@ -0,0 +1,111 @@
+package model
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mcp-system-control/approval"
+	"mcp-system-control/config/model/command"
+	"mcp-system-control/openapi"
+	"net/url"
+	"os"
+	"time"
+)
+
+type OpenAPI struct {
+	Spec       string            `yaml:"spec,omitempty" usage:"Path to the OpenAPI 3 specification file (JSON or YAML)"`
+	BaseUrl    string            `yaml:"baseUrl,omitempty" usage:"The base url of the API (default: the first server of the specification)"`
+	Operations []string          `yaml:"operations,omitempty" usage:"Only include the operations whose operationId (or tool name) matches one of these patterns. Example: listPets, get*"`
+	Tags       []string          `yaml:"tags,omitempty" usage:"Only include the operations with one of these tags"`
+	Prefix     string            `yaml:"prefix,omitempty" usage:"Prefix for the tool names"`
+	Header     map[string]string `yaml:"header,omitempty" usage:"Headers which will be sent with each call. Environment variables will be expanded. Example: X-Api-Key: ${API_KEY}"`
+	Query      map[string]string `yaml:"query,omitempty" usage:"Query parameters which will be sent with each call. Environment variables will be expanded"`
+	Auth       OpenAPIAuth       `yaml:"auth,omitempty" usage:"Authentication: "`
+	Timeout    time.Duration     `yaml:"timeout,omitempty" usage:"Timeout of each call"`
+	Approval   string            `yaml:"approval,omitempty" usage:"Expression to check if user approval is needed before execute a tool (default: always)"`
+}
+
+type OpenAPIAuth struct {
+	Bearer   string `yaml:"bearer,omitempty" usage:"Bearer token. Environment variables will be expanded. Example: ${API_TOKEN}"`
+	Username string `yaml:"username,omitempty" usage:"Username for basic authentication. Environment variables will be expanded"`
+	Password string `yaml:"password,omitempty" usage:"Password for basic authentication. Environment variables will be expanded"`
+}
+
+// Load reads the specification and converts each selected operation into a function definition.
+func (o *OpenAPI) Load(name string) ([]command.FunctionDefinition, error) {
+	if o.Spec == "" {
+		return nil, fmt.Errorf("specification of OpenAPI '%s' is missing", name)
+	}
+
+	toolApproval := o.Approval
+	if toolApproval == "" {
+		toolApproval = approval.Always
+	}
+	if err := approval.Approval(toolApproval).Validate(); err != nil {
+		return nil, fmt.Errorf("invalid approval expression of OpenAPI '%s': %w", name, err)
+	}
+
+	spec, err := openapi.Load(o.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := o.settings(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings of OpenAPI '%s': %w", name, err)
+	}
+
+	operations, err := spec.Select(o.Operations, o.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings of OpenAPI '%s': %w", name, err)
+	}
+
+	var result []command.FunctionDefinition
+	for _, op := range operations {
+		op.Name = o.Prefix + op.Name
+
+		fd, err := command.OpenAPIDefinition(op, settings, toolApproval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operation of OpenAPI '%s': %w", name, err)
+		}
+		result = append(result, fd)
+	}
+
+	return result, nil
+}
+
+func (o *OpenAPI) settings(spec *openapi.Spec) (openapi.Settings, error) {
+	settings := openapi.Settings{
+		BaseUrl:   os.ExpandEnv(o.BaseUrl),
+		Header:    map[string]string{},
+		Query:     map[string]string{},
+		TimeoutMs: o.Timeout.Milliseconds(),
+	}
+
+	if settings.BaseUrl == "" {
+		for _, server := range spec.Servers {
+			if u, err := url.Parse(server); err == nil && u.IsAbs() {
+				settings.BaseUrl = server
+				break
+			}
+		}
+	}
+	if settings.BaseUrl == "" {
+		return settings, fmt.Errorf("base url is missing (the specification contains no absolute server url)")
+	}
+
+	if o.Auth.Bearer != "" {
+		settings.Header["Authorization"] = "Bearer " + os.ExpandEnv(o.Auth.Bearer)
+	}
+	if o.Auth.Username != "" || o.Auth.Password != "" {
+		credentials := os.ExpandEnv(o.Auth.Username) + ":" + os.ExpandEnv(o.Auth.Password)
+		settings.Header["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+	for key, value := range o.Header {
+		settings.Header[key] = os.ExpandEnv(value)
+	}
+	for key, value := range o.Query {
+		settings.Query[key] = os.ExpandEnv(value)
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"fmt"
+	"mcp-system-control/approval"
+	"mcp-system-control/config/model/command"
+	"mcp-system-control/expression"
+	"mcp-system-control/wasm"
+)
+
+type Plugins struct {
+	Dir  string      `yaml:"dir,omitempty" usage:"Directory with JS plugin files (*.js). Each file exports one or more tools"`
+	Wasm WasmPlugins `yaml:"wasm,omitempty" usage:"[WASM] "`
+}
+
+type WasmPlugins struct {
+	Dir           string `yaml:"dir,omitempty" usage:"Directory with wasm plugin files (*.wasm). Each module exports one or more tools"`
+	RunApproval   string `yaml:"runApproval,omitempty" usage:"Expression to check if user approval is needed before a wasm plugin executes a command"`
+	FetchApproval string `yaml:"fetchApproval,omitempty" usage:"Expression to check if user approval is needed before a wasm plugin executes a http call"`
+}
+
+func (w *WasmPlugins) SetDefaults() {
+	if w.RunApproval == "" {
+		w.RunApproval = approval.Always
+	}
+	if w.FetchApproval == "" {
+		w.FetchApproval = approval.Always
+	}
+}
+
+// Load loads all tools of the configured plugin directories.
+func (p *Plugins) Load() ([]command.FunctionDefinition, error) {
+	var result []command.FunctionDefinition
+
+	if p.Dir != "" {
+		tools, err := expression.LoadPluginDir(p.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tool := range tools {
+			fd, err := command.PluginDefinition(tool)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, fd)
+		}
+	}
+
+	if p.Wasm.Dir != "" {
+		permissions, err := p.Wasm.permissions()
+		if err != nil {
+			return nil, err
+		}
+
+		tools, err := wasm.LoadDir(p.Wasm.Dir, permissions)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tool := range tools {
+			fd, err := command.WasmPluginDefinition(tool)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, fd)
+		}
+	}
+
+	return result, nil
+}
+
+func (w *WasmPlugins) permissions() (wasm.Permissions, error) {
+	w.SetDefaults()
+
+	permissions := wasm.Permissions{
+		RunApproval:   approval.Approval(w.RunApproval),
+		FetchApproval: approval.Approval(w.FetchApproval),
+	}
+	if err := permissions.RunApproval.Validate(); err != nil {
+		return permissions, fmt.Errorf("invalid run approval of wasm plugins: %w", err)
+	}
+	if err := permissions.FetchApproval.Validate(); err != nil {
+		return permissions, fmt.Errorf("invalid fetch approval of wasm plugins: %w", err)
+	}
+	return permissions, nil
+}
@@ -0,0 +1,8 @@
+package model
+
+type TestExpression struct {
+	Expression string `yaml:"test-expression,omitempty" usage:"Evaluates the given JavaScript expression (or path to JS-file) offline and prints the results"`
+	Context    string `yaml:"test-expression-ctx,omitempty" usage:"The JSON value (or path to JSON-file) which will be available as ctx variable inside the tested expression"`
+	Fixture    string `yaml:"test-expression-fixture,omitempty" usage:"Path to a fixture file (yaml or json) which contains the test cases with mocked responses for run and fetch"`
+	Live       bool   `yaml:"test-expression-live,omitempty" usage:"Execute the calls of run, exec, fetch, approve and sample for real if no fixture is given. Otherwise, these calls will fail"`
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"mcp-system-control/config/model/command"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ToolPolicy contains the settings which are applied to the tools depending on their annotations (hints).
+type ToolPolicy struct {
+	// ReadOnly only allows the tools which are annotated as read-only
+	ReadOnly bool
+	// ReadOnlyApproval overrides the approval of all read-only tools (if set)
+	ReadOnlyApproval string
+	// DestructiveApproval overrides the approval of all destructive tools (if set)
+	DestructiveApproval string
+}
+
+// ToolPolicy returns the policy for all tools of the server.
+func (c *Config) ToolPolicy() ToolPolicy {
+	return ToolPolicy{
+		ReadOnly:            c.ReadOnly,
+		ReadOnlyApproval:    c.Approval.ReadOnlyTools,
+		DestructiveApproval: c.Approval.DestructiveTools,
+	}
+}
+
+// IsReadOnly checks if the tool is annotated as read-only.
+func IsReadOnly(annotations mcp.ToolAnnotation) bool {
+	return annotations.ReadOnlyHint != nil && *annotations.ReadOnlyHint
+}
+
+// IsDestructive checks if the tool may perform destructive updates. Like the MCP specification
+// defines, a tool which is not read-only is destructive unless it is annotated otherwise.
+func IsDestructive(annotations mcp.ToolAnnotation) bool {
+	if IsReadOnly(annotations) {
+		return false
+	}
+	return annotations.DestructiveHint == nil || *annotations.DestructiveHint
+}
+
+// Allows checks if a tool with the given annotations may be provided.
+func (p ToolPolicy) Allows(annotations mcp.ToolAnnotation) bool {
+	return !p.ReadOnly || IsReadOnly(annotations)
+}
+
+// ApprovalFor returns the approval for a tool with the given annotations and its own approval.
+func (p ToolPolicy) ApprovalFor(annotations mcp.ToolAnnotation, toolApproval string) string {
+	if approval, overridden := p.override(annotations); overridden {
+		return approval
+	}
+	return toolApproval
+}
+
+func (p ToolPolicy) override(annotations mcp.ToolAnnotation) (string, bool) {
+	if p.ReadOnlyApproval != "" && IsReadOnly(annotations) {
+		return p.ReadOnlyApproval, true
+	}
+	if p.DestructiveApproval != "" && IsDestructive(annotations) {
+		return p.DestructiveApproval, true
+	}
+	return "", false
+}
+
+// Apply applies the policy to the given tool definition. It returns false if the tool is not allowed.
+func (p ToolPolicy) Apply(definition command.FunctionDefinition) (command.FunctionDefinition, bool) {
+	if !p.Allows(definition.Annotations) {
+		return definition, false
+	}
+
+	if approval, overridden := p.override(definition.Annotations); overridden {
+		definition.Approval = approval
+		// the approval function (e.g. of a plugin) would take precedence
+		definition.ApprovalFn = nil
+	}
+	return definition, true
+}
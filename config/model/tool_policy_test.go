@@ -0,0 +1,83 @@
+package model
+
+import (
+	"context"
+	"mcp-system-control/approval"
+	"mcp-system-control/config/model/command"
+	"mcp-system-control/mcp/server/builtin/tools/file"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolPolicy_Hints(t *testing.T) {
+	assert.True(t, IsReadOnly(file.StatsTool.Annotations))
+	assert.False(t, IsDestructive(file.StatsTool.Annotations))
+
+	assert.False(t, IsReadOnly(file.DirectoryDeletionTool.Annotations))
+	assert.True(t, IsDestructive(file.DirectoryDeletionTool.Annotations))
+
+	assert.False(t, IsReadOnly(file.FileAppendingTool.Annotations))
+	assert.False(t, IsDestructive(file.FileAppendingTool.Annotations))
+
+	// without any hints a tool is treated as destructive
+	assert.False(t, IsReadOnly(mcp.ToolAnnotation{}))
+	assert.True(t, IsDestructive(mcp.ToolAnnotation{}))
+}
+
+func TestToolPolicy_Apply(t *testing.T) {
+	readOnly := command.FunctionDefinition{
+		Approval:    approval.Always,
+		Annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+	}
+	additive := command.FunctionDefinition{
+		Approval:    "false",
+		Annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(false), DestructiveHint: mcp.ToBoolPtr(false)},
+	}
+	destructive := command.FunctionDefinition{
+		ApprovalFn: func(context.Context, string) bool { return false },
+	}
+
+	policy := ToolPolicy{ReadOnlyApproval: approval.Never, DestructiveApproval: approval.Always}
+
+	fd, allowed := policy.Apply(readOnly)
+	assert.True(t, allowed)
+	assert.Equal(t, approval.Never, fd.Approval)
+
+	fd, allowed = policy.Apply(additive)
+	assert.True(t, allowed)
+	assert.Equal(t, "false", fd.Approval)
+
+	fd, allowed = policy.Apply(destructive)
+	assert.True(t, allowed)
+	assert.Equal(t, approval.Always, fd.Approval)
+	assert.Nil(t, fd.ApprovalFn)
+	assert.True(t, fd.NeedApproval(context.Background(), "{}"))
+
+	policy = ToolPolicy{ReadOnly: true}
+
+	_, allowed = policy.Apply(readOnly)
+	assert.True(t, allowed)
+	_, allowed = policy.Apply(additive)
+	assert.False(t, allowed)
+	_, allowed = policy.Apply(destructive)
+	assert.False(t, allowed)
+}
+
+func TestBuiltIns_ApplyPolicy(t *testing.T) {
+	b := BuiltIns{}
+	b.Stats.Approval = approval.Always
+	b.DirectoryDeletion.Approval = approval.Never
+
+	b.ApplyPolicy(ToolPolicy{ReadOnly: true, ReadOnlyApproval: approval.Never, DestructiveApproval: approval.Always})
+
+	assert.False(t, b.Stats.Disable)
+	assert.Equal(t, approval.Never, b.GetApprovalFor(file.StatsTool.Name))
+	assert.True(t, b.DirectoryDeletion.Disable)
+	assert.Equal(t, approval.Always, b.GetApprovalFor(file.DirectoryDeletionTool.Name))
+	assert.True(t, b.CommandExec.Disable)
+	assert.True(t, b.FileAppending.Disable)
+	assert.False(t, b.FileReading.Disable)
+	assert.False(t, b.SystemTime.Disable)
+}
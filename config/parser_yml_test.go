@@ -5,6 +5,7 @@ import (
 	"mcp-system-control/config/model/command"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/rainu/go-yacl"
@@ -19,6 +20,8 @@ func Test_processYaml(t *testing.T) {
 builtin:
   command-execution:
     disable: true
+    timeout: 30s
+    failOnExitCode: true
 custom:
   test:
     description: This is a test function.
@@ -35,6 +38,10 @@ custom:
         - arg1
     command: doTest.sh
     approval: true
+    timeout: 1m
+    gracePeriod: 10s
+    successExitCodes: [0, 1]
+    failOnExitCode: true
 log-level: debug
 `
 	sr := strings.NewReader(yamlContent)
@@ -48,7 +55,9 @@ log-level: debug
 		},
 		BuiltIns: model.BuiltIns{
 			CommandExec: model.CommandExecution{
-				Disable: true,
+				Disable:        true,
+				Timeout:        30 * time.Second,
+				FailOnExitCode: true,
 			},
 		},
 		Custom: map[string]command.FunctionDefinition{
@@ -68,9 +77,81 @@ log-level: debug
 					},
 					Required: []string{"arg1"},
 				},
-				Command:  "doTest.sh",
-				Approval: "true",
+				Command:          "doTest.sh",
+				Approval:         "true",
+				Timeout:          time.Minute,
+				GracePeriod:      10 * time.Second,
+				SuccessExitCodes: []int{0, 1},
+				FailOnExitCode:   true,
 			},
 		},
 	}, c)
 }
+
+func Test_processYaml_ExpressionGlobals(t *testing.T) {
+	c := &model.Config{}
+
+	yamlContent := `
+expression:
+  globals:
+    team: platform
+    allowedPaths:
+      - /tmp
+      - /var/tmp
+  globalFiles:
+    settings: /path/to/settings.yaml
+  init:
+    - function isAllowed(p) { return allowedPaths.some(a => p.startsWith(a)) }
+`
+	sr := strings.NewReader(yamlContent)
+	config := yacl.NewConfig(c, yacl.WithAutoApplyDefaults(false))
+
+	require.NoError(t, processYaml(config, sr))
+
+	assert.Equal(t, model.Expression{
+		Globals: map[string]any{
+			"team":         "platform",
+			"allowedPaths": []any{"/tmp", "/var/tmp"},
+		},
+		GlobalFiles: map[string]string{
+			"settings": "/path/to/settings.yaml",
+		},
+		InitScripts: []string{
+			"function isAllowed(p) { return allowedPaths.some(a => p.startsWith(a)) }",
+		},
+	}, c.Expression)
+}
+
+func Test_processYaml_HTTP(t *testing.T) {
+	c := &model.Config{}
+
+	yamlContent := `
+custom:
+  get-user:
+    description: Returns the name of a user.
+    http:
+      method: GET
+      url: https://api.example.com/users/${id}
+      query:
+        fields: name
+      header:
+        Authorization: Bearer ${token}
+      expectedStatus: [200, 404]
+      extract: .name
+    timeout: 10s
+`
+	sr := strings.NewReader(yamlContent)
+	config := yacl.NewConfig(c, yacl.WithAutoApplyDefaults(false))
+
+	require.NoError(t, processYaml(config, sr))
+
+	assert.Equal(t, command.HTTP{
+		Method:         "GET",
+		Url:            "https://api.example.com/users/${id}",
+		Query:          map[string]string{"fields": "name"},
+		Header:         map[string]string{"Authorization": "Bearer ${token}"},
+		ExpectedStatus: []int{200, 404},
+		Extract:        ".name",
+	}, c.Custom["get-user"].HTTP)
+	assert.Equal(t, 10*time.Second, c.Custom["get-user"].Timeout)
+}
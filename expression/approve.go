@@ -0,0 +1,77 @@
+package expression
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp-system-control/approval/message"
+	"sync"
+
+	"github.com/dop251/goja"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const FuncNameApprove = "approve"
+
+// ApprovalRequester asks the user for approval (see approval.Requester).
+type ApprovalRequester interface {
+	WaitForApproval(ctx context.Context, request *mcp.CallToolRequest) (bool, error)
+}
+
+var approvalRequester ApprovalRequester
+var approvalMutex = sync.RWMutex{}
+
+// SetApprovalRequester sets the requester which will be used by the approve function.
+func SetApprovalRequester(requester ApprovalRequester) {
+	approvalMutex.Lock()
+	defer approvalMutex.Unlock()
+
+	approvalRequester = requester
+}
+
+// WaitForApproval asks the user for approval of the given request with the configured requester.
+func WaitForApproval(ctx context.Context, request *mcp.CallToolRequest) (bool, error) {
+	approvalMutex.RLock()
+	requester := approvalRequester
+	approvalMutex.RUnlock()
+
+	if requester == nil {
+		return false, fmt.Errorf("no approval requester available")
+	}
+	return requester.WaitForApproval(ctx, request)
+}
+
+// RequestApproval is used by the approve function to ask the user. It can be replaced (e.g. for mocking).
+var RequestApproval = func(ctx context.Context, msg, details string) (bool, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = message.ScriptApproval
+	request.Params.Arguments = map[string]any{
+		"message": msg,
+		"details": details,
+	}
+
+	return WaitForApproval(ctx, &request)
+}
+
+func approve(ctx context.Context, vm *goja.Runtime) func(string, goja.Value) bool {
+	return func(msg string, details goja.Value) bool {
+		var sDetails string
+		if details != nil && !goja.IsUndefined(details) && !goja.IsNull(details) {
+			if _, isObject := details.(*goja.Object); isObject {
+				raw, err := json.MarshalIndent(details.Export(), "", "  ")
+				if err != nil {
+					panic(vm.ToValue(err.Error()))
+				}
+				sDetails = string(raw)
+			} else {
+				sDetails = details.String()
+			}
+		}
+
+		approved, err := RequestApproval(ctx, msg, sDetails)
+		if err != nil {
+			panic(vm.ToValue(fmt.Sprintf("error while waiting for approval: %s", err.Error())))
+		}
+		return approved
+	}
+}
@@ -0,0 +1,61 @@
+package expression
+
+import (
+	"context"
+	"fmt"
+	"mcp-system-control/approval/message"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequester struct {
+	approved bool
+	err      error
+	requests []mcp.CallToolRequest
+}
+
+func (t *testRequester) WaitForApproval(ctx context.Context, request *mcp.CallToolRequest) (bool, error) {
+	t.requests = append(t.requests, *request)
+	return t.approved, t.err
+}
+
+func TestRun_Approve(t *testing.T) {
+	t.Cleanup(func() { SetApprovalRequester(nil) })
+
+	requester := &testRequester{approved: true}
+	SetApprovalRequester(requester)
+
+	s, err := Run(context.Background(), FuncNameApprove+`("Delete 2 files?", {files: ["a", "b"]}) ? "deleted" : "skipped"`, nil).AsString()
+	require.NoError(t, err)
+	assert.Equal(t, "deleted", s)
+
+	requester.approved = false
+	s, err = Run(context.Background(), FuncNameApprove+`("Delete file?", "/tmp/file") ? "deleted" : "skipped"`, nil).AsString()
+	require.NoError(t, err)
+	assert.Equal(t, "skipped", s)
+
+	require.Len(t, requester.requests, 2)
+	assert.Equal(t, message.ScriptApproval, requester.requests[0].Params.Name)
+	assert.Equal(t, map[string]any{
+		"message": "Delete 2 files?",
+		"details": "{\n  \"files\": [\n    \"a\",\n    \"b\"\n  ]\n}",
+	}, requester.requests[0].Params.Arguments)
+	assert.Equal(t, map[string]any{
+		"message": "Delete file?",
+		"details": "/tmp/file",
+	}, requester.requests[1].Params.Arguments)
+}
+
+func TestRun_Approve_Error(t *testing.T) {
+	t.Cleanup(func() { SetApprovalRequester(nil) })
+
+	_, err := Run(context.Background(), FuncNameApprove+`("Delete file?")`, nil).AsBoolean()
+	assert.ErrorContains(t, err, "error while waiting for approval: no approval requester available")
+
+	SetApprovalRequester(&testRequester{err: fmt.Errorf("timeout")})
+	_, err = Run(context.Background(), FuncNameApprove+`("Delete file?")`, nil).AsBoolean()
+	assert.ErrorContains(t, err, "error while waiting for approval: timeout")
+}
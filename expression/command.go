@@ -9,9 +9,14 @@ import (
 
 const FuncNameRun = "run"
 
+// RunCommand is used by the run function to execute the given command. It can be replaced (e.g. for mocking).
+var RunCommand = func(ctx context.Context, cmd command.CommandDescriptor) ([]byte, error) {
+	return cmd.Run(ctx)
+}
+
 func run(ctx context.Context, vm *goja.Runtime) func(command.CommandDescriptor) string {
 	return func(cmd command.CommandDescriptor) string {
-		r, err := cmd.Run(ctx)
+		r, err := RunCommand(ctx, cmd)
 		if err != nil {
 			panic(vm.ToValue(err.Error()))
 		}
@@ -0,0 +1,21 @@
+package expression
+
+import (
+	"context"
+
+	"github.com/dop251/goja"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const ObjNameConsole = "console"
+
+func console(ctx context.Context, vm *goja.Runtime) *goja.Object {
+	obj := vm.NewObject()
+	obj.Set("log", logFn(ctx, mcp.LoggingLevelInfo))
+	obj.Set("info", logFn(ctx, mcp.LoggingLevelInfo))
+	obj.Set("debug", logFn(ctx, mcp.LoggingLevelDebug))
+	obj.Set("trace", logFn(ctx, mcp.LoggingLevelDebug))
+	obj.Set("warn", logFn(ctx, mcp.LoggingLevelWarning))
+	obj.Set("error", logFn(ctx, mcp.LoggingLevelError))
+	return obj
+}
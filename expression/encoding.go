@@ -0,0 +1,43 @@
+package expression
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/dop251/goja"
+)
+
+const (
+	ObjNameBase64 = "base64"
+	ObjNameHex    = "hex"
+)
+
+func base64Encoding(vm *goja.Runtime) *goja.Object {
+	obj := vm.NewObject()
+	obj.Set("encode", func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	})
+	obj.Set("decode", func(s string) string {
+		r, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+		return string(r)
+	})
+	return obj
+}
+
+func hexEncoding(vm *goja.Runtime) *goja.Object {
+	obj := vm.NewObject()
+	obj.Set("encode", func(s string) string {
+		return hex.EncodeToString([]byte(s))
+	})
+	obj.Set("decode", func(s string) string {
+		r, err := hex.DecodeString(s)
+		if err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+		return string(r)
+	})
+	return obj
+}
@@ -0,0 +1,19 @@
+package expression
+
+import (
+	"os"
+
+	"github.com/dop251/goja"
+)
+
+const FuncNameEnv = "env"
+
+func env(vm *goja.Runtime) func(string) goja.Value {
+	return func(name string) goja.Value {
+		value, exists := os.LookupEnv(name)
+		if !exists {
+			return goja.Undefined()
+		}
+		return vm.ToValue(value)
+	}
+}
@@ -0,0 +1,26 @@
+package expression
+
+import (
+	"context"
+	"mcp-system-control/mcp/server/builtin/tools/command"
+
+	"github.com/dop251/goja"
+)
+
+const FuncNameExec = "exec"
+
+// ExecCommand is used by the exec function to execute the given command. It can be replaced (e.g. for mocking).
+var ExecCommand = func(ctx context.Context, cmd command.CommandDescriptor) (*command.ExecutionResult, error) {
+	return cmd.Exec(ctx)
+}
+
+func execFn(ctx context.Context, vm *goja.Runtime) func(command.CommandDescriptor) *command.ExecutionResult {
+	return func(cmd command.CommandDescriptor) *command.ExecutionResult {
+		r, err := ExecCommand(ctx, cmd)
+		if err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+
+		return r
+	}
+}
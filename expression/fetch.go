@@ -9,9 +9,14 @@ import (
 
 const FuncNameFetch = "fetch"
 
+// Fetch is used by the fetch function to execute the given http call. It can be replaced (e.g. for mocking).
+var Fetch = func(ctx context.Context, call http.CallDescriptor) (*http.CallResult, error) {
+	return call.Run(ctx, http.ClientFor(ctx))
+}
+
 func fetch(ctx context.Context, vm *goja.Runtime) func(http.CallDescriptor) *http.CallResult {
 	return func(call http.CallDescriptor) *http.CallResult {
-		r, err := call.Run(ctx, http.DefaultClient)
+		r, err := Fetch(ctx, call)
 		if err != nil {
 			panic(vm.ToValue(err.Error()))
 		}
@@ -1,5 +1,63 @@
 package expression
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/dop251/goja"
+)
+
 const VarNameContext = "ctx"
 
-var globalVariables = map[string]any{}
+// globalVariables contains the JSON of each global variable. Each runtime gets its own (frozen) copy,
+// so that an expression can neither change the values for other evaluations nor race with them.
+var globalVariables = map[string]string{}
+var initScripts []string
+var globalMutex = sync.RWMutex{}
+
+// SetGlobalVariables defines variables which will be available in every expression. The names must not
+// collide with builtin functions, objects or the context variable. The values are read-only.
+func SetGlobalVariables(variables map[string]any) error {
+	vm := goja.New()
+	if err := setupFunctions(context.Background(), vm); err != nil {
+		return fmt.Errorf("unable to initialize runtime: %w", err)
+	}
+
+	serialized := make(map[string]string, len(variables))
+	for name, value := range variables {
+		if name == VarNameContext || vm.Get(name) != nil {
+			return fmt.Errorf("global variable '%s' collides with a builtin name", name)
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("global variable '%s' is not serializable: %w", name, err)
+		}
+		serialized[name] = string(raw)
+	}
+
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	globalVariables = serialized
+	invalidateRuntimes()
+	return nil
+}
+
+// SetInitScripts defines scripts (inline or path to file) which will be executed in every expression
+// runtime before the expression itself. This can be used to define shared helper functions.
+func SetInitScripts(scripts []string) error {
+	for _, script := range scripts {
+		if err := Precompile(script); err != nil {
+			return fmt.Errorf("unable to compile init script '%s': %w", script, err)
+		}
+	}
+
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	initScripts = scripts
+	invalidateRuntimes()
+	return nil
+}
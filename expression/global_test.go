@@ -0,0 +1,111 @@
+package expression
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_GlobalsAndInitScripts(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, SetGlobalVariables(map[string]any{}))
+		require.NoError(t, SetInitScripts(nil))
+	})
+
+	scriptFile := filepath.Join(t.TempDir(), "init.js")
+	require.NoError(t, os.WriteFile(scriptFile, []byte(`function isAllowed(p) { return allowedPaths.some(a => p.startsWith(a)) }`), 0644))
+
+	require.NoError(t, SetGlobalVariables(map[string]any{
+		"allowedPaths": []any{"/tmp", "/var/tmp"},
+		"settings":     map[string]any{"team": "platform"},
+	}))
+	require.NoError(t, SetInitScripts([]string{
+		scriptFile,
+		`const greeting = "hello " + settings.team`,
+	}))
+
+	b, err := Run(context.Background(), `isAllowed(ctx.path)`, map[string]any{"path": "/tmp/file"}).AsBoolean()
+	require.NoError(t, err)
+	assert.True(t, b)
+
+	b, err = Run(context.Background(), `isAllowed(ctx.path)`, map[string]any{"path": "/etc/passwd"}).AsBoolean()
+	require.NoError(t, err)
+	assert.False(t, b)
+
+	s, err := Run(context.Background(), `greeting`, nil).AsString()
+	require.NoError(t, err)
+	assert.Equal(t, "hello platform", s)
+}
+
+func TestRun_GlobalsAreNotShared(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, SetGlobalVariables(map[string]any{}))
+	})
+
+	require.NoError(t, SetGlobalVariables(map[string]any{
+		"allowedPaths": []any{"/tmp", "/var/tmp"},
+		"settings":     map[string]any{"team": "platform"},
+	}))
+
+	check := `allowedPaths.join(",") + "|" + settings.team`
+	require.NoError(t, Precompile(check))
+
+	for _, expression := range []string{
+		`allowedPaths[0] = "/"; settings.team = "evil"`,
+		`allowedPaths = ["/"]; settings = {}`,
+	} {
+		// precompiled expressions use pooled runtimes, all others fresh ones
+		require.NoError(t, Precompile(expression))
+		require.NoError(t, Run(context.Background(), expression, nil).err)
+
+		s, err := Run(context.Background(), check, nil).AsString()
+		require.NoError(t, err)
+		assert.Equal(t, "/tmp,/var/tmp|platform", s)
+
+		s, err = Run(context.Background(), expression+`; `+check, nil).AsString()
+		require.NoError(t, err)
+		assert.Equal(t, "/tmp,/var/tmp|platform", s)
+	}
+
+	_, err := Run(context.Background(), `allowedPaths.push("/etc")`, nil).AsString()
+	assert.ErrorContains(t, err, "object is not extensible")
+}
+
+func TestRun_GlobalsConcurrent(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, SetGlobalVariables(map[string]any{}))
+	})
+
+	require.NoError(t, SetGlobalVariables(map[string]any{
+		"settings": map[string]any{"count": 0},
+	}))
+	expression := `settings.count++; settings.count`
+	require.NoError(t, Precompile(expression))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result, err := Run(context.Background(), expression, nil).AsString()
+			assert.NoError(t, err)
+			assert.Equal(t, "0", result)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSetGlobalVariables_Collision(t *testing.T) {
+	assert.EqualError(t, SetGlobalVariables(map[string]any{"ctx": "value"}), "global variable 'ctx' collides with a builtin name")
+	assert.EqualError(t, SetGlobalVariables(map[string]any{"run": "value"}), "global variable 'run' collides with a builtin name")
+}
+
+func TestSetInitScripts_Invalid(t *testing.T) {
+	assert.ErrorContains(t, SetInitScripts([]string{`function (`}), "unable to compile init script")
+}
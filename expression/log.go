@@ -1,13 +1,76 @@
 package expression
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 const FuncNameLog = "log"
 
+// LoggerName is the name of the logger which is used for log notifications to the MCP client
+const LoggerName = "expression"
+
 var Log = func(args ...interface{}) {
 	fmt.Fprint(os.Stderr, "EXPRESSION_LOG: ")
 	fmt.Fprintln(os.Stderr, args...)
 }
+
+type logCollectorKey struct{}
+
+// LogCollector collects all log messages of the expressions which are running with the corresponding context.
+type LogCollector struct {
+	mutex sync.Mutex
+	lines []string
+}
+
+// WithLogCollector returns a new context which collects all log messages of expressions running with it.
+func WithLogCollector(ctx context.Context) (context.Context, *LogCollector) {
+	collector := &LogCollector{}
+	return context.WithValue(ctx, logCollectorKey{}, collector), collector
+}
+
+func (l *LogCollector) add(level mcp.LoggingLevel, message string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.lines = append(l.lines, fmt.Sprintf("[%s] %s", level, message))
+}
+
+// Lines returns all collected log lines.
+func (l *LogCollector) Lines() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return append([]string{}, l.lines...)
+}
+
+func (l *LogCollector) String() string {
+	return strings.Join(l.Lines(), "\n")
+}
+
+// logFn writes the log message to Log, to the log collector (if any) and sends it as
+// log notification to the MCP client (if the expression is running in context of a MCP request).
+func logFn(ctx context.Context, level mcp.LoggingLevel) func(args ...interface{}) {
+	return func(args ...interface{}) {
+		Log(args...)
+
+		message := strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+		if collector, ok := ctx.Value(logCollectorKey{}).(*LogCollector); ok {
+			collector.add(level, message)
+		}
+
+		if s := server.ServerFromContext(ctx); s != nil {
+			err := s.SendLogMessageToClient(ctx, mcp.NewLoggingMessageNotification(level, LoggerName, message))
+			if err != nil {
+				slog.Debug("Unable to send log message to client.", "error", err)
+			}
+		}
+	}
+}
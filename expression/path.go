@@ -0,0 +1,33 @@
+package expression
+
+import (
+	"path/filepath"
+
+	"mcp-system-control/mcp/server/builtin/tools/file"
+
+	"github.com/dop251/goja"
+)
+
+const ObjNamePath = "path"
+
+func pathUtils(vm *goja.Runtime) *goja.Object {
+	obj := vm.NewObject()
+	obj.Set("join", func(elements ...string) string {
+		return filepath.Join(elements...)
+	})
+	obj.Set("dirname", func(p string) string {
+		return filepath.Dir(p)
+	})
+	obj.Set("resolve", func(elements ...string) string {
+		p, err := file.Path(filepath.Join(elements...)).Get()
+		if err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+		p, err = filepath.Abs(p)
+		if err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+		return p
+	})
+	return obj
+}
@@ -0,0 +1,257 @@
+package expression
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+const (
+	VarNameModule  = "module"
+	VarNameExports = "exports"
+
+	PluginFieldName        = "name"
+	PluginFieldDescription = "description"
+	PluginFieldInputSchema = "inputSchema"
+	PluginFieldAnnotations = "annotations"
+	PluginFieldApproval    = "approval"
+	PluginFieldHandler     = "handler"
+)
+
+// PluginTool is a tool which is defined by a JS plugin file. The file must export (module.exports) an
+// object (or a list of objects) with the tool metadata and a handler function.
+type PluginTool struct {
+	// the program is compiled once on load. Plugin files are not hot-reloaded, because the
+	// registered tool (name, schema, approval) would not be updated.
+	program *goja.Program
+	// index inside the exported list; -1 if a single object is exported
+	index int
+
+	Name        string
+	Description string
+	InputSchema map[string]any
+	Annotations map[string]any
+
+	// ApprovalFn is true if the plugin exports an approval function. Otherwise, Approval contains
+	// the exported (static) value.
+	ApprovalFn bool
+	Approval   any
+}
+
+// LoadPluginDir loads all JS plugin files (*.js) of the given directory.
+func LoadPluginDir(dir string) ([]PluginTool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read plugin directory: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var result []PluginTool
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".js") {
+			continue
+		}
+
+		tools, err := LoadPlugin(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tools...)
+	}
+
+	return result, nil
+}
+
+// LoadPlugin loads the tool definitions of the given JS plugin file.
+func LoadPlugin(path string) ([]PluginTool, error) {
+	program, err := compilePlugin(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compile plugin '%s': %w", path, err)
+	}
+
+	_, exports, err := pluginExports(context.Background(), program)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load plugin '%s': %w", path, err)
+	}
+
+	var objects []*goja.Object
+	index := -1
+	if exports.ClassName() == "Array" {
+		index = 0
+		for i, key := range exports.Keys() {
+			obj, isObject := exports.Get(key).(*goja.Object)
+			if !isObject {
+				return nil, fmt.Errorf("invalid plugin '%s': entry #%d must be an object", path, i)
+			}
+			objects = append(objects, obj)
+		}
+	} else {
+		objects = append(objects, exports)
+	}
+
+	var result []PluginTool
+	for i, obj := range objects {
+		tool := PluginTool{
+			program: program,
+			index:   index,
+		}
+		if index >= 0 {
+			tool.index = i
+		}
+
+		if err = tool.read(obj); err != nil {
+			return nil, fmt.Errorf("invalid plugin '%s': %w", path, err)
+		}
+		result = append(result, tool)
+	}
+
+	return result, nil
+}
+
+func (p *PluginTool) read(obj *goja.Object) error {
+	p.Name = exportString(obj.Get(PluginFieldName))
+	if p.Name == "" {
+		return fmt.Errorf("missing %s", PluginFieldName)
+	}
+	p.Description = exportString(obj.Get(PluginFieldDescription))
+
+	if _, isFn := goja.AssertFunction(obj.Get(PluginFieldHandler)); !isFn {
+		return fmt.Errorf("tool '%s': %s must be a function", p.Name, PluginFieldHandler)
+	}
+
+	var isMap bool
+	if v := obj.Get(PluginFieldInputSchema); v != nil && !goja.IsUndefined(v) {
+		if p.InputSchema, isMap = v.Export().(map[string]any); !isMap {
+			return fmt.Errorf("tool '%s': %s must be an object", p.Name, PluginFieldInputSchema)
+		}
+	}
+	if v := obj.Get(PluginFieldAnnotations); v != nil && !goja.IsUndefined(v) {
+		if p.Annotations, isMap = v.Export().(map[string]any); !isMap {
+			return fmt.Errorf("tool '%s': %s must be an object", p.Name, PluginFieldAnnotations)
+		}
+	}
+
+	if v := obj.Get(PluginFieldApproval); v != nil && !goja.IsUndefined(v) {
+		if _, isFn := goja.AssertFunction(v); isFn {
+			p.ApprovalFn = true
+		} else {
+			p.Approval = v.Export()
+		}
+	}
+
+	return nil
+}
+
+func exportString(v goja.Value) string {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return ""
+	}
+	return v.String()
+}
+
+func compilePlugin(path string) (*goja.Program, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	program, _, err := compileFile(file)
+	return program, err
+}
+
+// pluginExports runs the plugin program in a fresh runtime and returns the exported value.
+func pluginExports(ctx context.Context, program *goja.Program) (*goja.Runtime, *goja.Object, error) {
+	vm, err := initRuntime(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to initialize runtime: %w", err)
+	}
+
+	module := vm.NewObject()
+	exports := vm.NewObject()
+	if err = module.Set(VarNameExports, exports); err != nil {
+		return nil, nil, err
+	}
+	if err = vm.Set(VarNameModule, module); err != nil {
+		return nil, nil, err
+	}
+	if err = vm.Set(VarNameExports, exports); err != nil {
+		return nil, nil, err
+	}
+
+	if _, err = vm.RunProgram(program); err != nil {
+		return nil, nil, jsError(err)
+	}
+
+	exported := module.Get(VarNameExports)
+	if exported == nil || goja.IsUndefined(exported) || goja.IsNull(exported) {
+		return nil, nil, fmt.Errorf("nothing exported")
+	}
+	obj, isObject := exported.(*goja.Object)
+	if !isObject {
+		return nil, nil, fmt.Errorf("the exported value must be an object or a list of objects")
+	}
+
+	return vm, obj, nil
+}
+
+// call calls the function with the given name of the plugin tool. The ctxVal will be passed as
+// argument and is also available as global variable.
+func (p *PluginTool) call(ctx context.Context, fnName string, ctxVal any) *Result {
+	vm, exports, err := pluginExports(ctx, p.program)
+	if err != nil {
+		return &Result{err: fmt.Errorf("unable to load plugin: %w", err)}
+	}
+
+	tool := exports
+	if p.index >= 0 {
+		var isObject bool
+		if tool, isObject = exports.Get(strconv.Itoa(p.index)).(*goja.Object); !isObject {
+			return &Result{err: fmt.Errorf("plugin does not export tool '%s' anymore", p.Name)}
+		}
+	}
+	if name := exportString(tool.Get(PluginFieldName)); name != p.Name {
+		return &Result{err: fmt.Errorf("plugin does not export tool '%s' anymore (found '%s')", p.Name, name)}
+	}
+
+	fn, isFn := goja.AssertFunction(tool.Get(fnName))
+	if !isFn {
+		return &Result{err: fmt.Errorf("%s is not a function", fnName)}
+	}
+
+	if err = vm.Set(VarNameContext, ctxVal); err != nil {
+		return &Result{err: fmt.Errorf("unable to set %s variable: %w", VarNameContext, err)}
+	}
+
+	v, err := fn(tool, vm.Get(VarNameContext))
+	if err != nil {
+		return &Result{err: jsError(err)}
+	}
+	return &Result{result: v}
+}
+
+// Handle calls the handler function of the plugin tool.
+func (p *PluginTool) Handle(ctx context.Context, ctxVal any) *Result {
+	return p.call(ctx, PluginFieldHandler, ctxVal)
+}
+
+// CallApproval calls the approval function of the plugin tool.
+func (p *PluginTool) CallApproval(ctx context.Context, ctxVal any) *Result {
+	return p.call(ctx, PluginFieldApproval, ctxVal)
+}
+
+func jsError(err error) error {
+	var jsErr *goja.Exception
+	if errors.As(err, &jsErr) {
+		// report the whole stack trace (including file and line of the source expression)
+		return fmt.Errorf("unable to run expression: %s", strings.TrimSpace(jsErr.String()))
+	}
+	return fmt.Errorf("unable to run expression: %w", err)
+}
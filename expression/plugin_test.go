@@ -0,0 +1,136 @@
+package expression
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPluginDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a_single.js"), []byte(`
+module.exports = {
+	name: "greet",
+	description: "Greets somebody.",
+	inputSchema: { type: "object", properties: { name: { type: "string" } }, required: ["name"] },
+	annotations: { readOnlyHint: true },
+	approval: (ctx) => ctx.args.name === "root",
+	handler: (ctx) => "Hello " + ctx.args.name,
+}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b_multi.js"), []byte(`
+function helper(v) { return v * 2 }
+
+exports.tools = "ignored";
+module.exports = [
+	{ name: "double", handler: (ctx) => helper(ctx.args.value), approval: false },
+	{ name: "triple", handler: (ctx) => ctx.args.value * 3, approval: "cel: true" },
+]
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "readme.md"), []byte(`not a plugin`), 0644))
+
+	tools, err := LoadPluginDir(dir)
+	require.NoError(t, err)
+	require.Len(t, tools, 3)
+
+	assert.Equal(t, "greet", tools[0].Name)
+	assert.Equal(t, "Greets somebody.", tools[0].Description)
+	assert.Equal(t, map[string]any{"readOnlyHint": true}, tools[0].Annotations)
+	assert.Equal(t, "object", tools[0].InputSchema["type"])
+	assert.True(t, tools[0].ApprovalFn)
+
+	assert.Equal(t, "double", tools[1].Name)
+	assert.False(t, tools[1].ApprovalFn)
+	assert.Equal(t, false, tools[1].Approval)
+	assert.Equal(t, "cel: true", tools[2].Approval)
+
+	s, err := tools[0].Handle(context.Background(), map[string]any{"args": map[string]any{"name": "World"}}).AsString()
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World", s)
+
+	b, err := tools[0].CallApproval(context.Background(), map[string]any{"args": map[string]any{"name": "root"}}).AsBoolean()
+	require.NoError(t, err)
+	assert.True(t, b)
+
+	f, err := tools[1].Handle(context.Background(), map[string]any{"args": map[string]any{"value": 2}}).AsFloat()
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, f)
+
+	f, err = tools[2].Handle(context.Background(), map[string]any{"args": map[string]any{"value": 2}}).AsFloat()
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, f)
+}
+
+func TestLoadPlugin_NoReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugin.js")
+	require.NoError(t, os.WriteFile(path, []byte(`module.exports = [
+	{ name: "first", handler: () => "first" },
+	{ name: "second", handler: () => "second" },
+]`), 0644))
+
+	tools, err := LoadPlugin(path)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+
+	// the registered tools can not be changed, so the plugin file must not be reloaded
+	require.NoError(t, os.WriteFile(path, []byte(`module.exports = [{ name: "other", handler: () => "other" }]`), 0644))
+	reloadChanged()
+
+	s, err := tools[1].Handle(context.Background(), nil).AsString()
+	require.NoError(t, err)
+	assert.Equal(t, "second", s)
+}
+
+func TestPluginTool_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugin.js")
+	require.NoError(t, os.WriteFile(path, []byte(`module.exports = `+FuncNameEnv+`("PLUGIN_TEST_SHRINK") ? [
+	{ name: "renamed", handler: () => "renamed" },
+] : [
+	{ name: "first", handler: () => "first" },
+	{ name: "second", handler: () => "second" },
+]`), 0644))
+
+	tools, err := LoadPlugin(path)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+
+	t.Setenv("PLUGIN_TEST_SHRINK", "true")
+
+	_, err = tools[1].Handle(context.Background(), nil).AsString()
+	assert.EqualError(t, err, "plugin does not export tool 'second' anymore")
+
+	_, err = tools[0].Handle(context.Background(), nil).AsString()
+	assert.EqualError(t, err, "plugin does not export tool 'first' anymore (found 'renamed')")
+}
+
+func TestLoadPlugin_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"nothing exported", `module.exports = undefined`, "nothing exported"},
+		{"no object", `module.exports = "tool"`, "the exported value must be an object or a list of objects"},
+		{"null entry", `module.exports = [null]`, "invalid plugin"},
+		{"undefined entry", `module.exports = [{ name: "test", handler: () => "" }, undefined]`, "entry #1 must be an object"},
+		{"primitive entry", `module.exports = ["tool"]`, "entry #0 must be an object"},
+		{"missing name", `module.exports = { handler: () => "" }`, "missing name"},
+		{"missing handler", `module.exports = { name: "test" }`, "tool 'test': handler must be a function"},
+		{"invalid schema", `module.exports = { name: "test", handler: () => "", inputSchema: "object" }`, "tool 'test': inputSchema must be an object"},
+		{"syntax error", `module.exports = {`, "unable to compile plugin"},
+		{"runtime error", `throw "broken"`, "broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plugin.js")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			_, err := LoadPlugin(path)
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
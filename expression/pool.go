@@ -0,0 +1,148 @@
+package expression
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// pooledRuntime is a runtime which can be reused for multiple evaluations. The creation of a
+// runtime (including the execution of the init scripts) is much more expensive than the reset.
+type pooledRuntime struct {
+	vm         *goja.Runtime
+	ctx        *runtimeContext
+	generation uint64
+
+	// all global properties after the initialization
+	globals map[string]goja.Value
+}
+
+var runtimeGeneration = atomic.Uint64{}
+
+// invalidateRuntimes prevents that already pooled runtimes will be reused. This must be called
+// whenever the initial state of a runtime changes (global variables, init scripts).
+func invalidateRuntimes() {
+	runtimeGeneration.Add(1)
+}
+
+func acquireRuntime(ctx context.Context, pool *sync.Pool) (*pooledRuntime, error) {
+	generation := runtimeGeneration.Load()
+
+	for {
+		rt, ok := pool.Get().(*pooledRuntime)
+		if !ok {
+			break
+		}
+		if rt.generation != generation {
+			continue
+		}
+
+		if err := rt.reset(ctx); err != nil {
+			return nil, err
+		}
+		return rt, nil
+	}
+
+	rCtx := &runtimeContext{}
+	rCtx.set(ctx)
+
+	vm, err := initRuntime(rCtx)
+	if err != nil {
+		return nil, err
+	}
+	// the runtime will be reused, so the evaluations must not be able to modify the shared objects
+	if _, err = vm.RunProgram(harden); err != nil {
+		return nil, fmt.Errorf("unable to harden runtime: %w", err)
+	}
+	if err = vm.Set(VarNameContext, goja.Undefined()); err != nil {
+		return nil, fmt.Errorf("unable to set %s variable: %w", VarNameContext, err)
+	}
+
+	rt := &pooledRuntime{
+		vm:         vm,
+		ctx:        rCtx,
+		generation: generation,
+		globals:    map[string]goja.Value{},
+	}
+	for _, name := range vm.GlobalObject().GetOwnPropertyNames() {
+		rt.globals[name] = vm.Get(name)
+	}
+	return rt, nil
+}
+
+func (r *pooledRuntime) reset(ctx context.Context) error {
+	r.vm.ClearInterrupt()
+
+	// the functions are bound to the runtime context, so they will use the new context from now on
+	r.ctx.set(ctx)
+
+	// restore reassigned globals (e.g. functions of the init scripts)
+	for name, value := range r.globals {
+		if current := r.vm.Get(name); current != nil && current.SameAs(value) {
+			continue
+		}
+		if err := r.vm.Set(name, value); err != nil {
+			return fmt.Errorf("unable to reset %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// releaseRuntime puts the runtime back into the pool. If the evaluation has left new global
+// properties (e.g. var or function declarations), the runtime will be discarded. Reassigned
+// globals will be restored on the next acquisition. The builtin objects can not be modified,
+// because they are frozen on the creation of the runtime (see harden).
+func releaseRuntime(rt *pooledRuntime, pool *sync.Pool) {
+	if rt.generation != runtimeGeneration.Load() {
+		return
+	}
+
+	names := rt.vm.GlobalObject().GetOwnPropertyNames()
+	if len(names) != len(rt.globals) {
+		return
+	}
+	for _, name := range names {
+		if _, known := rt.globals[name]; !known {
+			return
+		}
+	}
+
+	if err := rt.vm.Set(VarNameContext, goja.Undefined()); err != nil {
+		return
+	}
+	// do not hold the context of the last evaluation
+	rt.ctx.set(context.Background())
+
+	pool.Put(rt)
+}
+
+// runtimeContext is a context which delegates to an exchangeable context. So the functions of a
+// pooled runtime can be bound once and will always use the context of the current evaluation.
+type runtimeContext struct {
+	mutex sync.RWMutex
+	ctx   context.Context
+}
+
+func (r *runtimeContext) set(ctx context.Context) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ctx = ctx
+}
+
+func (r *runtimeContext) current() context.Context {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.ctx
+}
+
+func (r *runtimeContext) Deadline() (time.Time, bool) { return r.current().Deadline() }
+func (r *runtimeContext) Done() <-chan struct{}       { return r.current().Done() }
+func (r *runtimeContext) Err() error                  { return r.current().Err() }
+func (r *runtimeContext) Value(key any) any           { return r.current().Value(key) }
@@ -0,0 +1,135 @@
+package expression
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_Pooled_State(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{"var declaration", `var counter = (typeof counter === 'undefined') ? 1 : counter + 1; counter`, "1"},
+		{"implicit global", `implicitCounter = (typeof implicitCounter === 'undefined') ? 1 : implicitCounter + 1; implicitCounter`, "1"},
+		{"lexical declaration", `let lexicalCounter = 1; lexicalCounter`, "1"},
+		{"class declaration", `class Foo {}; "1"`, "1"},
+		{"reassign builtin", `const before = typeof ` + FuncNameRun + `; ` + FuncNameRun + ` = null; before === "function" ? "1" : "0"`, "1"},
+		{"reassign standard object", `const before = typeof JSON.stringify; JSON = null; before === "function" ? "1" : "0"`, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, Precompile(tt.expression))
+
+			for i := 0; i < 3; i++ {
+				s, err := Run(context.Background(), tt.expression, nil).AsString()
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, s)
+			}
+		})
+	}
+}
+
+func TestRun_Pooled_Builtins(t *testing.T) {
+	tamper := ObjNamePath + `.join = () => "/etc/passwd"; Array.prototype.includes = () => true; Object.prototype.admin = true; true`
+	check := `[` + ObjNamePath + `.join("a", "b"), ["x"].includes("rm"), ({}).admin === undefined].join(",")`
+	require.NoError(t, Precompile(tamper))
+	require.NoError(t, Precompile(check))
+
+	for i := 0; i < 3; i++ {
+		_, err := Run(context.Background(), tamper, nil).AsBoolean()
+		require.NoError(t, err)
+
+		s, err := Run(context.Background(), check, nil).AsString()
+		require.NoError(t, err)
+		assert.Equal(t, "a/b,false,true", s)
+	}
+
+	strict := `"use strict"; Array.prototype.includes = () => true`
+	require.NoError(t, Precompile(strict))
+	_, err := Run(context.Background(), strict, nil).AsString()
+	assert.ErrorContains(t, err, "Cannot assign to read only property 'includes'")
+}
+
+func TestRun_Pooled_Separated(t *testing.T) {
+	defer func() { require.NoError(t, SetInitScripts(nil)) }()
+	require.NoError(t, SetInitScripts([]string{`const allowed = ["ls"]`}))
+
+	tamper := `allowed.push("rm"); allowed.length`
+	check := `allowed.includes("rm")`
+	require.NoError(t, Precompile(tamper))
+	require.NoError(t, Precompile(check))
+
+	_, err := Run(context.Background(), tamper, nil).AsFloat()
+	require.NoError(t, err)
+
+	// the runtimes are not shared between different expressions
+	b, err := Run(context.Background(), check, nil).AsBoolean()
+	require.NoError(t, err)
+	assert.False(t, b)
+}
+
+func TestRun_Pooled_Context(t *testing.T) {
+	expression := FuncNameSleep + `(1); ` + VarNameContext + `.value`
+	require.NoError(t, Precompile(expression))
+
+	s, err := Run(context.Background(), expression, map[string]any{"value": "first"}).AsString()
+	require.NoError(t, err)
+	assert.Equal(t, "first", s)
+
+	// the functions of a reused runtime must be bound to the new context
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Run(canceled, expression, map[string]any{"value": "second"}).AsString()
+	assert.ErrorContains(t, err, "context canceled")
+
+	s, err = Run(context.Background(), expression, map[string]any{"value": "third"}).AsString()
+	require.NoError(t, err)
+	assert.Equal(t, "third", s)
+}
+
+func TestRun_Pooled_Concurrent(t *testing.T) {
+	expression := VarNameContext + `.value * 2`
+	require.NoError(t, Precompile(expression))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			f, err := Run(context.Background(), expression, map[string]any{"value": i}).AsFloat()
+			assert.NoError(t, err)
+			assert.Equal(t, float64(i*2), f)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkRun(b *testing.B) {
+	expression := VarNameContext + `.args.path.startsWith("/tmp")`
+	ctxVal := map[string]any{"args": map[string]any{"path": "/tmp/file"}}
+
+	b.Run("fresh runtime", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			vm, err := initRuntime(context.Background())
+			require.NoError(b, err)
+			require.NoError(b, vm.Set(VarNameContext, ctxVal))
+			_, err = vm.RunString(expression)
+			require.NoError(b, err)
+		}
+	})
+
+	require.NoError(b, Precompile(expression))
+	b.Run("precompiled and pooled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := Run(context.Background(), expression, ctxVal).AsBoolean()
+			require.NoError(b, err)
+		}
+	})
+}
@@ -1,36 +1,187 @@
 package expression
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/dop251/goja"
+	"github.com/dop251/goja/ast"
 	"github.com/dop251/goja/parser"
 )
 
-var precompiledPrograms = map[string]*goja.Program{}
+type precompiledProgram struct {
+	program *goja.Program
+
+	// true if the program declares top-level let/const/class bindings. Such bindings can not be
+	// removed from a runtime, so the runtime can not be reused afterward.
+	lexical bool
+
+	// only set if the program was compiled from a file
+	isFile  bool
+	modTime time.Time
+	size    int64
+
+	// the runtimes which can be reused for this program. Each program has its own pool, so that
+	// evaluations of different programs never share a runtime.
+	runtimes *sync.Pool
+}
+
+var precompiledPrograms = map[string]*precompiledProgram{}
+var precompiledMutex = sync.RWMutex{}
 
 func Precompile(source string) error {
 	file, err := os.Open(source)
 	if err == nil && file != nil {
 		defer file.Close()
 
-		ast, err := parser.ParseFile(nil, file.Name(), file, 0)
+		fs, err := file.Stat()
 		if err != nil {
-			return fmt.Errorf("error parsing file: %w", err)
+			return fmt.Errorf("error reading file stats: %w", err)
 		}
-		prog, err := goja.CompileAST(ast, false)
+
+		prog, lexical, err := compileFile(file)
 		if err != nil {
-			return fmt.Errorf("error compiling file: %w", err)
+			return err
 		}
-		precompiledPrograms[source] = prog
+		setPrecompiled(source, &precompiledProgram{
+			program: prog,
+			lexical: lexical,
+			isFile:  true,
+			modTime: fs.ModTime(),
+			size:    fs.Size(),
+		})
 	} else {
-		prog, err := goja.Compile("", source, false)
+		prog, lexical, err := compile("", source)
 		if err != nil {
-			return fmt.Errorf("error compiling file: %w", err)
+			return err
 		}
-		precompiledPrograms[source] = prog
+		setPrecompiled(source, &precompiledProgram{program: prog, lexical: lexical})
 	}
 
 	return nil
 }
+
+func compileFile(file *os.File) (*goja.Program, bool, error) {
+	return compile(file.Name(), file)
+}
+
+func compile(name string, src any) (*goja.Program, bool, error) {
+	program, err := parser.ParseFile(nil, name, src, 0)
+	if err != nil {
+		return nil, false, fmt.Errorf("error parsing file: %w", err)
+	}
+	prog, err := goja.CompileAST(program, false)
+	if err != nil {
+		return nil, false, fmt.Errorf("error compiling file: %w", err)
+	}
+	return prog, hasLexicalDeclaration(program), nil
+}
+
+func hasLexicalDeclaration(program *ast.Program) bool {
+	for _, stmt := range program.Body {
+		switch stmt.(type) {
+		case *ast.LexicalDeclaration, *ast.ClassDeclaration:
+			return true
+		}
+	}
+	return false
+}
+
+func setPrecompiled(source string, p *precompiledProgram) {
+	precompiledMutex.Lock()
+	defer precompiledMutex.Unlock()
+
+	p.runtimes = &sync.Pool{}
+	precompiledPrograms[source] = p
+	invalidateRuntimes()
+}
+
+func getPrecompiled(source string) (precompiledProgram, bool) {
+	precompiledMutex.RLock()
+	defer precompiledMutex.RUnlock()
+
+	p, found := precompiledPrograms[source]
+	if !found {
+		return precompiledProgram{}, false
+	}
+	return *p, true
+}
+
+// WatchPrecompiled checks all precompiled file sources periodically for changes and recompiles them.
+// If the recompilation fails, the last working version will be kept. This function blocks until the
+// given context is done.
+func WatchPrecompiled(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloadChanged()
+		}
+	}
+}
+
+func reloadChanged() {
+	precompiledMutex.RLock()
+	var changed []string
+	for source, p := range precompiledPrograms {
+		if !p.isFile {
+			continue
+		}
+		fs, err := os.Stat(source)
+		if err != nil {
+			continue
+		}
+		if !fs.ModTime().Equal(p.modTime) || fs.Size() != p.size {
+			changed = append(changed, source)
+		}
+	}
+	precompiledMutex.RUnlock()
+
+	for _, source := range changed {
+		reload(source)
+	}
+}
+
+func reload(source string) {
+	file, err := os.Open(source)
+	if err != nil {
+		slog.Error("Unable to reload expression file.", "file", source, "error", err)
+		return
+	}
+	defer file.Close()
+
+	fs, err := file.Stat()
+	if err != nil {
+		slog.Error("Unable to reload expression file.", "file", source, "error", err)
+		return
+	}
+
+	prog, lexical, err := compileFile(file)
+
+	precompiledMutex.Lock()
+	defer precompiledMutex.Unlock()
+
+	p := precompiledPrograms[source]
+
+	// remember the current state, so that a broken file will not be compiled again and again
+	p.modTime = fs.ModTime()
+	p.size = fs.Size()
+
+	if err != nil {
+		slog.Error("Unable to reload expression file. The last working version will be kept.", "file", source, "error", err)
+		return
+	}
+	p.program = prog
+	p.lexical = lexical
+	invalidateRuntimes()
+
+	slog.Info("Expression file reloaded.", "file", source)
+}
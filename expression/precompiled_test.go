@@ -0,0 +1,40 @@
+package expression
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchPrecompiled(t *testing.T) {
+	source := path.Join(t.TempDir(), "expression.js")
+	require.NoError(t, os.WriteFile(source, []byte(`"v1"`), 0644))
+	require.NoError(t, Precompile(source))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go WatchPrecompiled(ctx, 10*time.Millisecond)
+
+	result := func() string {
+		r, err := Run(context.Background(), source, nil).AsString()
+		require.NoError(t, err)
+		return r
+	}
+	assert.Equal(t, "v1", result())
+
+	require.NoError(t, os.WriteFile(source, []byte(`"v2 - changed"`), 0644))
+	assert.Eventually(t, func() bool { return result() == "v2 - changed" }, time.Second, 10*time.Millisecond)
+
+	// broken files should not replace the last working version
+	require.NoError(t, os.WriteFile(source, []byte(`"v3 broken`), 0644))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "v2 - changed", result())
+
+	require.NoError(t, os.WriteFile(source, []byte(`"v4 - fixed"`), 0644))
+	assert.Eventually(t, func() bool { return result() == "v4 - fixed" }, time.Second, 10*time.Millisecond)
+}
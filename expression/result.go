@@ -1,9 +1,17 @@
 package expression
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/dop251/goja"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ToolResultFieldContent           = "content"
+	ToolResultFieldStructuredContent = "structuredContent"
+	ToolResultFieldIsError           = "isError"
 )
 
 type Result struct {
@@ -51,3 +59,91 @@ func (r *Result) AsByteArray() ([]byte, error) {
 
 	return []byte(r.result.String()), nil
 }
+
+// AsToolResult interprets the result as MCP tool result. If the result is an object which contains
+// at least one of the fields "content", "structuredContent" or "isError", it will be converted into
+// the corresponding mcp.CallToolResult. Otherwise, the result will be treated as plain text.
+func (r *Result) AsToolResult() (*mcp.CallToolResult, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	obj, isObject := r.result.(*goja.Object)
+	if !isObject || obj.ClassName() != "Object" {
+		return mcp.NewToolResultText(r.result.String()), nil
+	}
+
+	exported, isMap := obj.Export().(map[string]any)
+	if !isMap {
+		return mcp.NewToolResultText(r.result.String()), nil
+	}
+
+	result, err := ToolResultFromMap(exported)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return mcp.NewToolResultText(r.result.String()), nil
+	}
+	return result, nil
+}
+
+// ToolResultFromMap converts the given map into a mcp.CallToolResult if it contains at least one of the
+// fields "content", "structuredContent" or "isError". Otherwise, nil will be returned.
+func ToolResultFromMap(exported map[string]any) (*mcp.CallToolResult, error) {
+	_, hasContent := exported[ToolResultFieldContent]
+	_, hasStructured := exported[ToolResultFieldStructuredContent]
+	_, hasIsError := exported[ToolResultFieldIsError]
+	if !hasContent && !hasStructured && !hasIsError {
+		return nil, nil
+	}
+
+	// plain strings inside the content are treated as text content
+	var content []any
+	if rawContent, ok := exported[ToolResultFieldContent].([]any); ok {
+		for _, part := range rawContent {
+			if s, isString := part.(string); isString {
+				content = append(content, mcp.NewTextContent(s))
+			} else {
+				content = append(content, part)
+			}
+		}
+	} else if hasContent {
+		return nil, fmt.Errorf("%s must be an array", ToolResultFieldContent)
+	}
+	if len(content) == 0 && hasStructured {
+		// for backward compatibility the structured content should also be available as text
+		rawStructured, err := json.Marshal(exported[ToolResultFieldStructuredContent])
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize %s: %w", ToolResultFieldStructuredContent, err)
+		}
+		content = append(content, mcp.NewTextContent(string(rawStructured)))
+	}
+
+	toSerialize := map[string]any{}
+	for k, v := range exported {
+		toSerialize[k] = v
+	}
+	toSerialize[ToolResultFieldContent] = append([]any{}, content...)
+
+	raw, err := json.Marshal(toSerialize)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize tool result: %w", err)
+	}
+	rawMessage := json.RawMessage(raw)
+
+	result, err := mcp.ParseCallToolResult(&rawMessage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tool result: %w", err)
+	}
+	return result, nil
+}
+
+// Export returns the raw result as go value.
+func (r *Result) Export() (any, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return r.result.Export(), nil
+}
@@ -5,62 +5,164 @@ import (
 	"fmt"
 
 	"github.com/dop251/goja"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func initRuntime(ctx context.Context) (vm *goja.Runtime, err error) {
 	vm = goja.New()
+	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
 
-	//setup functions
-	err = vm.Set(FuncNameLog, Log)
-	if err != nil {
-		return nil, fmt.Errorf("unable to set %s function: %w", FuncNameLog, err)
+	if err = setupFunctions(ctx, vm); err != nil {
+		return nil, err
 	}
 
-	err = vm.Set(FuncNameRun, run(ctx, vm))
-	if err != nil {
-		return nil, fmt.Errorf("unable to set %s function: %w", FuncNameRun, err)
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
+	if err = setupGlobals(vm); err != nil {
+		return nil, err
+	}
+
+	//run init scripts
+	for _, script := range initScripts {
+		p, found := getPrecompiled(script)
+		if !found {
+			return nil, fmt.Errorf("init script was not compiled: %s", script)
+		}
+		if _, err = vm.RunProgram(p.program); err != nil {
+			return nil, fmt.Errorf("unable to run init script: %w", err)
+		}
+	}
+
+	return
+}
+
+// harden freezes all global objects (the builtin objects, the functions and objects of this package and the
+// ones of the init scripts) including their prototypes. So an expression can not modify them for the following
+// evaluations of a pooled runtime. The global object itself stays extensible, reassigned globals will be
+// restored by the pool.
+var harden = goja.MustCompile("harden.js", `(function () {
+	const freeze = (v) => {
+		if (v !== null && (typeof v === "object" || typeof v === "function") && v !== globalThis && !Object.isFrozen(v)) {
+			Object.freeze(v);
+			freeze(Object.getPrototypeOf(v));
+			if (typeof v === "function") {
+				freeze(v.prototype);
+			} else {
+				Object.values(v).forEach(freeze);
+			}
+		}
+	};
+	Object.getOwnPropertyNames(globalThis).forEach((key) => freeze(globalThis[key]));
+})()`, true)
+
+func setupFunctions(ctx context.Context, vm *goja.Runtime) error {
+	functions := []struct {
+		name  string
+		value any
+	}{
+		{FuncNameLog, logFn(ctx, mcp.LoggingLevelInfo)},
+		{FuncNameRun, run(ctx, vm)},
+		{FuncNameExec, execFn(ctx, vm)},
+		{FuncNameFetch, fetch(ctx, vm)},
+		{FuncNameCallTool, callTool(ctx, vm)},
+		{FuncNameApprove, approve(ctx, vm)},
+		{FuncNameSample, sample(ctx, vm)},
+		{FuncNameEnv, env(vm)},
+		{FuncNameSleep, sleep(ctx, vm)},
+		{FuncNameSha256, sha256Hash},
+		{FuncNameMd5, md5Hash},
+		{FuncNameUUID, uuid},
+		{FuncNameShellQuote, shellQuote(vm)},
+		{FuncNameTemplate, template},
+		{ObjNameConsole, console(ctx, vm)},
+		{ObjNameBase64, base64Encoding(vm)},
+		{ObjNameHex, hexEncoding(vm)},
+		{ObjNamePath, pathUtils(vm)},
+	}
+	for _, f := range functions {
+		if err := vm.Set(f.name, f.value); err != nil {
+			return fmt.Errorf("unable to set %s function: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// frozenCopy parses the given JSON and freezes the result (deeply).
+var frozenCopy = goja.MustCompile("globals.js", `(function (json) {
+	const freeze = (v) => {
+		if (v !== null && typeof v === "object") {
+			Object.values(v).forEach(freeze);
+			Object.freeze(v);
+		}
+		return v;
+	};
+	return freeze(JSON.parse(json));
+})`, true)
+
+// setupGlobals sets the global variables. Each runtime gets its own frozen copy of the values.
+// The caller must hold the globalMutex.
+func setupGlobals(vm *goja.Runtime) error {
+	if len(globalVariables) == 0 {
+		return nil
 	}
 
-	err = vm.Set(FuncNameFetch, fetch(ctx, vm))
+	fn, err := vm.RunProgram(frozenCopy)
 	if err != nil {
-		return nil, fmt.Errorf("unable to set %s function: %w", FuncNameFetch, err)
+		return fmt.Errorf("unable to initialize global variables: %w", err)
 	}
+	copyFn, _ := goja.AssertFunction(fn)
 
-	//setup global variables
-	for key, value := range globalVariables {
-		err = vm.Set(key, value)
+	for key, raw := range globalVariables {
+		value, err := copyFn(goja.Undefined(), vm.ToValue(raw))
 		if err != nil {
-			return nil, fmt.Errorf("unable to set %s variable: %w", key, err)
+			return fmt.Errorf("unable to copy %s variable: %w", key, err)
+		}
+		if err = vm.GlobalObject().DefineDataProperty(key, value, goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE); err != nil {
+			return fmt.Errorf("unable to set %s variable: %w", key, err)
 		}
 	}
-
-	return
+	return nil
 }
 
 func Run(ctx context.Context, expression string, ctxVal any) *Result {
-	vm, err := initRuntime(ctx)
+	p, wasPrecompiled := getPrecompiled(expression)
+	if !wasPrecompiled {
+		// unknown expressions will be executed in a fresh runtime, because we do not know what they are doing
+		vm, err := initRuntime(ctx)
+		if err != nil {
+			return &Result{err: fmt.Errorf("unable to initialize runtime: %w", err)}
+		}
+		return evaluate(vm, ctxVal, func() (goja.Value, error) {
+			return vm.RunString(expression)
+		})
+	}
+
+	rt, err := acquireRuntime(ctx, p.runtimes)
 	if err != nil {
 		return &Result{err: fmt.Errorf("unable to initialize runtime: %w", err)}
 	}
+	result := evaluate(rt.vm, ctxVal, func() (goja.Value, error) {
+		return rt.vm.RunProgram(p.program)
+	})
+	if _, isObject := result.result.(*goja.Object); !p.lexical && !isObject {
+		// objects are bound to their runtime, so the runtime can only be reused for primitive results
+		releaseRuntime(rt, p.runtimes)
+	}
+
+	return result
+}
 
+func evaluate(vm *goja.Runtime, ctxVal any, exec func() (goja.Value, error)) *Result {
 	// set additional variable
-	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
-	err = vm.Set(VarNameContext, ctxVal)
+	err := vm.Set(VarNameContext, ctxVal)
 	if err != nil {
 		return &Result{err: fmt.Errorf("unable to set %s variable: %w", VarNameContext, err)}
 	}
 
-	var v goja.Value
-
-	prog, wasPrecompiled := precompiledPrograms[expression]
-	if wasPrecompiled {
-		v, err = vm.RunProgram(prog)
-	} else {
-		v, err = vm.RunString(expression)
-	}
-
+	v, err := exec()
 	if err != nil {
-		return &Result{err: fmt.Errorf("unable to run expression: %w", err)}
+		return &Result{err: jsError(err)}
 	}
 
 	return &Result{result: v}
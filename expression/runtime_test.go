@@ -0,0 +1,96 @@
+package expression
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_StandardLibrary(t *testing.T) {
+	t.Setenv("MCP_SYSTEM_CONTROL_TEST_ENV", "test-value")
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	tests := []struct {
+		expression string
+		expected   string
+		pattern    string
+	}{
+		{expression: `env("MCP_SYSTEM_CONTROL_TEST_ENV")`, expected: "test-value"},
+		{expression: `env("MCP_SYSTEM_CONTROL_DOES_NOT_EXIST") === undefined`, expected: "true"},
+		{expression: `base64.encode("hello world")`, expected: "aGVsbG8gd29ybGQ="},
+		{expression: `base64.decode("aGVsbG8gd29ybGQ=")`, expected: "hello world"},
+		{expression: `hex.encode("hello")`, expected: "68656c6c6f"},
+		{expression: `hex.decode("68656c6c6f")`, expected: "hello"},
+		{expression: `sha256("hello")`, expected: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{expression: `md5("hello")`, expected: "5d41402abc4b2a76b9719d911017c592"},
+		{expression: `path.join("/tmp", "dir", "..", "file")`, expected: "/tmp/file"},
+		{expression: `path.dirname("/tmp/dir/file")`, expected: "/tmp/dir"},
+		{expression: `path.resolve("~", "file")`, expected: filepath.Join(home, "file")},
+		{expression: `path.resolve("file")`, expected: filepath.Join(wd, "file")},
+		{expression: `uuid()`, pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{expression: `shellQuote("echo", "hello world", "it's")`, expected: `echo 'hello world' "it's"`},
+		{expression: `template("Hello {{ name }} from {{address.city}}{{unknown}}!", {name: "rainu", address: {city: "Berlin"}})`, expected: "Hello rainu from Berlin!"},
+		{expression: `template("{{list}} {{count}}", {list: [1, 2], count: 3})`, expected: "[1,2] 3"},
+		{expression: `const path = "shadowed"; path`, expected: "shadowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			result, err := Run(context.Background(), tt.expression, nil).AsString()
+			require.NoError(t, err)
+
+			if tt.pattern != "" {
+				assert.Regexp(t, tt.pattern, result)
+			} else {
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRun_StandardLibrary_Console(t *testing.T) {
+	origLog := Log
+	defer func() {
+		Log = origLog
+	}()
+
+	var logged [][]any
+	Log = func(args ...interface{}) {
+		logged = append(logged, args)
+	}
+
+	_, err := Run(context.Background(), `console.log("a", 1); console.error("b"); true`, nil).AsBoolean()
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]any{{"a", int64(1)}, {"b"}}, logged)
+}
+
+func TestRun_StandardLibrary_Sleep(t *testing.T) {
+	start := time.Now()
+	_, err := Run(context.Background(), `sleep(20); true`, nil).AsBoolean()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = Run(ctx, `sleep(10000); true`, nil).AsBoolean()
+	assert.ErrorContains(t, err, "context deadline exceeded")
+}
+
+func TestRun_StandardLibrary_DecodeError(t *testing.T) {
+	_, err := Run(context.Background(), `base64.decode("###")`, nil).AsString()
+	assert.Error(t, err)
+
+	_, err = Run(context.Background(), `hex.decode("xyz")`, nil).AsString()
+	assert.Error(t, err)
+}
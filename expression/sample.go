@@ -0,0 +1,122 @@
+package expression
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dop251/goja"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const FuncNameSample = "sample"
+
+const defaultSampleMaxTokens = 1024
+
+type SampleDescriptor struct {
+	// Messages can be plain strings (user messages) or objects with role and content.
+	Messages     []any   `json:"messages"`
+	MaxTokens    int     `json:"maxTokens,omitempty"`
+	SystemPrompt string  `json:"systemPrompt,omitempty"`
+	Temperature  float64 `json:"temperature,omitempty"`
+}
+
+type sampleMessage struct {
+	Role    mcp.Role `json:"role"`
+	Content string   `json:"content"`
+}
+
+// Sample is used by the sample function to request a completion from the client. It can be replaced (e.g. for mocking).
+var Sample = func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	s := server.ServerFromContext(ctx)
+	if s == nil {
+		return nil, fmt.Errorf("no mcp server available")
+	}
+
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, fmt.Errorf("no active mcp session")
+	}
+	if ci, ok := session.(server.SessionWithClientInfo); ok && ci.GetClientCapabilities().Sampling == nil {
+		return nil, fmt.Errorf("the mcp client does not support sampling")
+	}
+
+	return s.RequestSampling(ctx, request)
+}
+
+func (s SampleDescriptor) toRequest() (mcp.CreateMessageRequest, error) {
+	request := mcp.CreateMessageRequest{}
+	request.SystemPrompt = s.SystemPrompt
+	request.Temperature = s.Temperature
+	request.MaxTokens = s.MaxTokens
+	if request.MaxTokens <= 0 {
+		request.MaxTokens = defaultSampleMaxTokens
+	}
+
+	if len(s.Messages) == 0 {
+		return request, fmt.Errorf("at least one message is required")
+	}
+	for i, m := range s.Messages {
+		msg := sampleMessage{Role: mcp.RoleUser}
+
+		switch v := m.(type) {
+		case string:
+			msg.Content = v
+		case map[string]any:
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return request, fmt.Errorf("invalid message #%d: %w", i, err)
+			}
+			if err = json.Unmarshal(raw, &msg); err != nil {
+				return request, fmt.Errorf("invalid message #%d: %w", i, err)
+			}
+			if msg.Role == "" {
+				msg.Role = mcp.RoleUser
+			}
+		default:
+			return request, fmt.Errorf("invalid message #%d: must be a string or an object with role and content", i)
+		}
+
+		request.Messages = append(request.Messages, mcp.SamplingMessage{
+			Role:    msg.Role,
+			Content: mcp.NewTextContent(msg.Content),
+		})
+	}
+
+	return request, nil
+}
+
+func resultText(result *mcp.CreateMessageResult) (string, error) {
+	switch c := result.Content.(type) {
+	case mcp.TextContent:
+		return c.Text, nil
+	case *mcp.TextContent:
+		return c.Text, nil
+	case map[string]any:
+		if text, ok := c["text"].(string); ok {
+			return text, nil
+		}
+	}
+	return "", fmt.Errorf("the sampling result contains no text")
+}
+
+func sample(ctx context.Context, vm *goja.Runtime) func(SampleDescriptor) string {
+	return func(desc SampleDescriptor) string {
+		request, err := desc.toRequest()
+		if err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+
+		result, err := Sample(ctx, request)
+		if err != nil {
+			panic(vm.ToValue(fmt.Sprintf("sampling failed: %s", err.Error())))
+		}
+
+		text, err := resultText(result)
+		if err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+		return text
+	}
+}
@@ -0,0 +1,25 @@
+package expression
+
+import (
+	"strings"
+
+	"github.com/dop251/goja"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+const FuncNameShellQuote = "shellQuote"
+
+// shellQuote quotes all given arguments, so that they can be safely used in a command line
+func shellQuote(vm *goja.Runtime) func(...string) string {
+	return func(args ...string) string {
+		quoted := make([]string, 0, len(args))
+		for _, arg := range args {
+			q, err := syntax.Quote(arg, syntax.LangBash)
+			if err != nil {
+				panic(vm.ToValue(err.Error()))
+			}
+			quoted = append(quoted, q)
+		}
+		return strings.Join(quoted, " ")
+	}
+}
@@ -0,0 +1,20 @@
+package expression
+
+import (
+	"context"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+const FuncNameSleep = "sleep"
+
+func sleep(ctx context.Context, vm *goja.Runtime) func(int64) {
+	return func(ms int64) {
+		select {
+		case <-ctx.Done():
+			panic(vm.ToValue(ctx.Err().Error()))
+		case <-time.After(time.Duration(ms) * time.Millisecond):
+		}
+	}
+}
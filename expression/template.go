@@ -0,0 +1,43 @@
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const FuncNameTemplate = "template"
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*}}`)
+
+// template replaces all placeholders like {{name}} or {{nested.name}} with the corresponding value of the given variables.
+// Unknown placeholders will be replaced with an empty string.
+func template(str string, vars map[string]any) string {
+	return templatePlaceholder.ReplaceAllStringFunc(str, func(placeholder string) string {
+		name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+
+		var value any = vars
+		for _, part := range strings.Split(name, ".") {
+			m, isMap := value.(map[string]any)
+			if !isMap {
+				return ""
+			}
+			value = m[part]
+		}
+
+		switch v := value.(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		case fmt.Stringer:
+			return v.String()
+		case map[string]any, []any:
+			raw, _ := json.Marshal(v)
+			return string(raw)
+		default:
+			return fmt.Sprintf("%v", v)
+		}
+	})
+}
@@ -0,0 +1,305 @@
+package tester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mcp-system-control/config/model"
+	"mcp-system-control/expression"
+	"mcp-system-control/mcp/server/builtin/tools/command"
+	"mcp-system-control/mcp/server/builtin/tools/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type Fixture struct {
+	Cases []Case `yaml:"cases" json:"cases"`
+}
+
+type Case struct {
+	Name  string      `yaml:"name" json:"name"`
+	Ctx   any         `yaml:"ctx" json:"ctx"`
+	Run   []RunMock   `yaml:"run" json:"run"`
+	Exec  []ExecMock  `yaml:"exec" json:"exec"`
+	Fetch []FetchMock `yaml:"fetch" json:"fetch"`
+
+	Approve []ApproveMock `yaml:"approve" json:"approve"`
+	Sample  []SampleMock  `yaml:"sample" json:"sample"`
+}
+
+type RunMock struct {
+	// optional matchers: if set, the mock will only be used for commands with the same name/command line
+	Name        string `yaml:"name" json:"name"`
+	CommandLine string `yaml:"command" json:"command"`
+
+	Output string `yaml:"output" json:"output"`
+	Error  string `yaml:"error" json:"error"`
+}
+
+type ExecMock struct {
+	// optional matchers: if set, the mock will only be used for commands with the same name/command line
+	Name        string `yaml:"name" json:"name"`
+	CommandLine string `yaml:"command" json:"command"`
+
+	Result command.ExecutionResult `yaml:"result" json:"result"`
+	Error  string                  `yaml:"error" json:"error"`
+}
+
+type FetchMock struct {
+	// optional matchers: if set, the mock will only be used for calls with the same method/url
+	Method string `yaml:"method" json:"method"`
+	Url    string `yaml:"url" json:"url"`
+
+	Response http.CallResult `yaml:"response" json:"response"`
+	Error    string          `yaml:"error" json:"error"`
+}
+
+type ApproveMock struct {
+	// optional matcher: if set, the mock will only be used for approvals with the same message
+	Message string `yaml:"message" json:"message"`
+
+	Approved bool   `yaml:"approved" json:"approved"`
+	Error    string `yaml:"error" json:"error"`
+}
+
+type SampleMock struct {
+	Text  string `yaml:"text" json:"text"`
+	Error string `yaml:"error" json:"error"`
+}
+
+// Run evaluates the configured expression offline and writes the results to the given output.
+func Run(ctx context.Context, output io.Writer, cfg model.TestExpression) error {
+	if err := expression.Precompile(cfg.Expression); err != nil {
+		return err
+	}
+
+	fixture := Fixture{}
+	if cfg.Fixture != "" {
+		raw, err := os.ReadFile(cfg.Fixture)
+		if err != nil {
+			return fmt.Errorf("unable to read fixture file: %w", err)
+		}
+		if err = yaml.Unmarshal(raw, &fixture); err != nil {
+			return fmt.Errorf("unable to parse fixture file: %w", err)
+		}
+	}
+
+	var defaultCtx any
+	if cfg.Context != "" {
+		rawCtx := []byte(cfg.Context)
+		if content, err := os.ReadFile(cfg.Context); err == nil {
+			rawCtx = content
+		}
+		if err := json.Unmarshal(rawCtx, &defaultCtx); err != nil {
+			return fmt.Errorf("unable to parse ctx: %w", err)
+		}
+	}
+
+	if len(fixture.Cases) == 0 {
+		// without fixture the expression will be evaluated once. The calls will fail (there are no mocks)
+		// unless live calls are enabled explicitly
+		runCase(ctx, output, cfg.Expression, Case{Ctx: defaultCtx}, !cfg.Live)
+		return nil
+	}
+
+	for i, c := range fixture.Cases {
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("#%d", i+1)
+		}
+		if c.Ctx == nil {
+			c.Ctx = defaultCtx
+		}
+
+		if i > 0 {
+			fmt.Fprintln(output)
+		}
+		fmt.Fprintf(output, "Case: %s\n", c.Name)
+		runCase(ctx, output, cfg.Expression, c, true)
+	}
+
+	return nil
+}
+
+func runCase(ctx context.Context, output io.Writer, expr string, c Case, mock bool) {
+	origLog, origRun, origExec, origFetch := expression.Log, expression.RunCommand, expression.ExecCommand, expression.Fetch
+	origApprove, origSample := expression.RequestApproval, expression.Sample
+	defer func() {
+		expression.Log, expression.RunCommand, expression.ExecCommand, expression.Fetch = origLog, origRun, origExec, origFetch
+		expression.RequestApproval, expression.Sample = origApprove, origSample
+	}()
+
+	logs := strings.Builder{}
+	expression.Log = func(args ...interface{}) {
+		logs.WriteString(fmt.Sprintln(args...))
+	}
+	if mock {
+		expression.RunCommand = c.mockRun()
+		expression.ExecCommand = c.mockExec()
+		expression.Fetch = c.mockFetch()
+		expression.RequestApproval = c.mockApprove()
+		expression.Sample = c.mockSample()
+	}
+
+	start := time.Now()
+	result := expression.Run(ctx, expr, c.Ctx)
+	duration := time.Since(start)
+
+	if raw, err := result.Export(); err != nil {
+		fmt.Fprintf(output, "Error:     %s\n", err.Error())
+	} else {
+		jsonResult, jErr := json.Marshal(raw)
+		if jErr != nil {
+			jsonResult = []byte(fmt.Sprintf("%v", raw))
+		}
+		fmt.Fprintf(output, "Result:    %s\n", jsonResult)
+
+		b, _ := result.AsBoolean()
+		fmt.Fprintf(output, "AsBoolean: %t\n", b)
+
+		s, _ := result.AsString()
+		fmt.Fprintf(output, "AsString:  %s\n", s)
+	}
+
+	fmt.Fprintf(output, "Duration:  %s\n", duration)
+	fmt.Fprintf(output, "Log:\n")
+	for _, line := range strings.Split(strings.TrimSuffix(logs.String(), "\n"), "\n") {
+		if line != "" {
+			fmt.Fprintf(output, "  %s\n", line)
+		}
+	}
+}
+
+func (c *Case) mockRun() func(context.Context, command.CommandDescriptor) ([]byte, error) {
+	used := make([]bool, len(c.Run))
+
+	return func(ctx context.Context, cmd command.CommandDescriptor) ([]byte, error) {
+		for i, m := range c.Run {
+			if used[i] {
+				continue
+			}
+			if m.Name != "" && m.Name != cmd.Name {
+				continue
+			}
+			if m.CommandLine != "" && m.CommandLine != cmd.CommandLine {
+				continue
+			}
+			used[i] = true
+
+			if m.Error != "" {
+				return []byte(m.Output), fmt.Errorf("%s", m.Error)
+			}
+			return []byte(m.Output), nil
+		}
+
+		return nil, fmt.Errorf("no mock available for command: %s", commandLineOf(cmd))
+	}
+}
+
+func (c *Case) mockExec() func(context.Context, command.CommandDescriptor) (*command.ExecutionResult, error) {
+	used := make([]bool, len(c.Exec))
+
+	return func(ctx context.Context, cmd command.CommandDescriptor) (*command.ExecutionResult, error) {
+		for i, m := range c.Exec {
+			if used[i] {
+				continue
+			}
+			if m.Name != "" && m.Name != cmd.Name {
+				continue
+			}
+			if m.CommandLine != "" && m.CommandLine != cmd.CommandLine {
+				continue
+			}
+			used[i] = true
+
+			if m.Error != "" {
+				return nil, fmt.Errorf("%s", m.Error)
+			}
+			result := m.Result
+			return &result, nil
+		}
+
+		return nil, fmt.Errorf("no mock available for command: %s", commandLineOf(cmd))
+	}
+}
+
+func commandLineOf(cmd command.CommandDescriptor) string {
+	if cmd.CommandLine != "" {
+		return cmd.CommandLine
+	}
+	return strings.TrimSpace(cmd.Name + " " + strings.Join(cmd.Arguments, " "))
+}
+
+func (c *Case) mockFetch() func(context.Context, http.CallDescriptor) (*http.CallResult, error) {
+	used := make([]bool, len(c.Fetch))
+
+	return func(ctx context.Context, call http.CallDescriptor) (*http.CallResult, error) {
+		for i, m := range c.Fetch {
+			if used[i] {
+				continue
+			}
+			if m.Method != "" && !strings.EqualFold(m.Method, call.Method) {
+				continue
+			}
+			if m.Url != "" && m.Url != call.Url {
+				continue
+			}
+			used[i] = true
+
+			if m.Error != "" {
+				return nil, fmt.Errorf("%s", m.Error)
+			}
+			response := m.Response
+			return &response, nil
+		}
+
+		return nil, fmt.Errorf("no mock available for http call: %s %s", call.Method, call.Url)
+	}
+}
+
+func (c *Case) mockApprove() func(context.Context, string, string) (bool, error) {
+	used := make([]bool, len(c.Approve))
+
+	return func(ctx context.Context, message, details string) (bool, error) {
+		for i, m := range c.Approve {
+			if used[i] {
+				continue
+			}
+			if m.Message != "" && m.Message != message {
+				continue
+			}
+			used[i] = true
+
+			if m.Error != "" {
+				return false, fmt.Errorf("%s", m.Error)
+			}
+			return m.Approved, nil
+		}
+
+		return false, fmt.Errorf("no mock available for approval: %s", message)
+	}
+}
+
+func (c *Case) mockSample() func(context.Context, mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	next := 0
+
+	return func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		if next >= len(c.Sample) {
+			return nil, fmt.Errorf("no mock available for sampling")
+		}
+		m := c.Sample[next]
+		next++
+
+		if m.Error != "" {
+			return nil, fmt.Errorf("%s", m.Error)
+		}
+		result := &mcp.CreateMessageResult{}
+		result.Role = mcp.RoleAssistant
+		result.Content = mcp.NewTextContent(m.Text)
+		return result, nil
+	}
+}
@@ -0,0 +1,200 @@
+package tester
+
+import (
+	"bytes"
+	"context"
+	"mcp-system-control/config/model"
+	"os"
+	"path"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var durationPattern = regexp.MustCompile(`Duration:  .*`)
+var stackPattern = regexp.MustCompile(`(?m)^\tat .*\n`)
+
+// normalize removes the values which depend on the environment (duration and stack trace)
+func normalize(output string) string {
+	return stackPattern.ReplaceAllString(durationPattern.ReplaceAllString(output, "Duration:  XXX"), "")
+}
+
+func TestRun(t *testing.T) {
+	fixture := path.Join(t.TempDir(), "fixture.yml")
+	require.NoError(t, os.WriteFile(fixture, []byte(`
+cases:
+  - name: command
+    ctx: {path: "/tmp/test"}
+    run:
+      - name: ls
+        output: "file1"
+  - name: http
+    fetch:
+      - method: GET
+        url: https://example.com
+        response:
+          status_code: 404
+  - name: missing mock
+    ctx: {path: "/tmp/test"}
+`), 0644))
+
+	output := bytes.Buffer{}
+	err := Run(context.Background(), &output, model.TestExpression{
+		Expression: `log("path:", ctx.path); ctx.path.startsWith("/tmp") ? run({name: "ls"}) : fetch({method: "GET", url: "https://example.com"}).status_code`,
+		Context:    `{"path": "/etc"}`,
+		Fixture:    fixture,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `Case: command
+Result:    "file1"
+AsBoolean: true
+AsString:  file1
+Duration:  XXX
+Log:
+  path: /tmp/test
+
+Case: http
+Result:    404
+AsBoolean: true
+AsString:  404
+Duration:  XXX
+Log:
+  path: /etc
+
+Case: missing mock
+Error:     unable to run expression: no mock available for command: ls
+Duration:  XXX
+Log:
+  path: /tmp/test
+`, normalize(output.String()))
+}
+
+func TestRun_WithoutFixture_NoLiveCalls(t *testing.T) {
+	output := bytes.Buffer{}
+	err := Run(context.Background(), &output, model.TestExpression{
+		Expression: `run({command: "echo hello"})`,
+	})
+	require.NoError(t, err)
+
+	// without fixture there are no mocks, so the command must not be executed
+	assert.Contains(t, output.String(), "Error:     unable to run expression: no mock available for command: echo hello\n")
+}
+
+func TestRun_Live(t *testing.T) {
+	output := bytes.Buffer{}
+	err := Run(context.Background(), &output, model.TestExpression{
+		Expression: `run({command: "echo hello"})`,
+		Live:       true,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, output.String(), "AsString:  hello\n")
+}
+
+func TestRun_Exec(t *testing.T) {
+	fixture := path.Join(t.TempDir(), "fixture.yml")
+	require.NoError(t, os.WriteFile(fixture, []byte(`
+cases:
+  - name: exec
+    exec:
+      - command: ls /tmp
+        result:
+          exitCode: 2
+          stderr: "no such file"
+`), 0644))
+
+	output := bytes.Buffer{}
+	err := Run(context.Background(), &output, model.TestExpression{
+		Expression: `exec({command: "ls /tmp"}).exitCode === 2`,
+		Fixture:    fixture,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `Case: exec
+Result:    true
+AsBoolean: true
+AsString:  true
+Duration:  XXX
+Log:
+`, durationPattern.ReplaceAllString(output.String(), "Duration:  XXX"))
+}
+
+func TestRun_Approve(t *testing.T) {
+	fixture := path.Join(t.TempDir(), "fixture.yml")
+	require.NoError(t, os.WriteFile(fixture, []byte(`
+cases:
+  - name: approved
+    approve:
+      - message: "Delete?"
+        approved: true
+  - name: denied
+    approve:
+      - approved: false
+`), 0644))
+
+	output := bytes.Buffer{}
+	err := Run(context.Background(), &output, model.TestExpression{
+		Expression: `approve("Delete?")`,
+		Fixture:    fixture,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `Case: approved
+Result:    true
+AsBoolean: true
+AsString:  true
+Duration:  XXX
+Log:
+
+Case: denied
+Result:    false
+AsBoolean: false
+AsString:  false
+Duration:  XXX
+Log:
+`, durationPattern.ReplaceAllString(output.String(), "Duration:  XXX"))
+}
+
+func TestRun_Sample(t *testing.T) {
+	fixture := path.Join(t.TempDir(), "fixture.yml")
+	require.NoError(t, os.WriteFile(fixture, []byte(`
+cases:
+  - name: sample
+    sample:
+      - text: "dangerous"
+`), 0644))
+
+	output := bytes.Buffer{}
+	err := Run(context.Background(), &output, model.TestExpression{
+		Expression: `sample({messages: ["Classify: rm -rf /"]}) === "dangerous"`,
+		Fixture:    fixture,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "Result:    true\n")
+}
+
+func TestRun_WithoutFixture(t *testing.T) {
+	output := bytes.Buffer{}
+	err := Run(context.Background(), &output, model.TestExpression{
+		Expression: `ctx.value * 2`,
+		Context:    `{"value": 21}`,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `Result:    42
+AsBoolean: true
+AsString:  42
+Duration:  XXX
+Log:
+`, durationPattern.ReplaceAllString(output.String(), "Duration:  XXX"))
+}
+
+func TestRun_InvalidExpression(t *testing.T) {
+	err := Run(context.Background(), &bytes.Buffer{}, model.TestExpression{
+		Expression: `(`,
+	})
+	assert.Error(t, err)
+}
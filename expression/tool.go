@@ -0,0 +1,71 @@
+package expression
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dop251/goja"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const FuncNameCallTool = "callTool"
+
+// MaxCallDepth is the maximum number of nested tool calls (e.g. a tool which calls itself).
+const MaxCallDepth = 8
+
+type callDepthKey struct{}
+
+func callDepthFrom(ctx context.Context) int {
+	depth, _ := ctx.Value(callDepthKey{}).(int)
+	return depth
+}
+
+// CallTool calls the tool with the given name of the MCP server from the context. The call is subject
+// to the tool's approval.
+func CallTool(ctx context.Context, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	depth := callDepthFrom(ctx)
+	if depth >= MaxCallDepth {
+		return nil, fmt.Errorf("maximum call depth of %d exceeded (calling tool '%s')", MaxCallDepth, name)
+	}
+	ctx = context.WithValue(ctx, callDepthKey{}, depth+1)
+
+	s := server.ServerFromContext(ctx)
+	if s == nil {
+		return nil, fmt.Errorf("no mcp server available")
+	}
+
+	tool := s.GetTool(name)
+	if tool == nil {
+		return nil, fmt.Errorf("tool '%s' not found", name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+
+	// the registered handler is the one which is wrapped by the approval handling
+	return tool.Handler(ctx, request)
+}
+
+func callTool(ctx context.Context, vm *goja.Runtime) func(string, map[string]any) map[string]any {
+	return func(name string, args map[string]any) map[string]any {
+		r, err := CallTool(ctx, name, args)
+		if err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+
+		// convert to plain js object so that the script can access the fields by their json names
+		raw, err := json.Marshal(r)
+		if err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+		result := map[string]any{}
+		if err = json.Unmarshal(raw, &result); err != nil {
+			panic(vm.ToValue(err.Error()))
+		}
+
+		return result
+	}
+}
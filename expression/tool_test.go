@@ -0,0 +1,33 @@
+package expression
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallTool_Recursion(t *testing.T) {
+	calls := 0
+
+	s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("recurse"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		_, err := Run(ctx, FuncNameCallTool+`("recurse", {})`, nil).AsString()
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "recurse", "arguments": {}}}`))
+	raw, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(raw), "maximum call depth of 8 exceeded (calling tool 'recurse')")
+	assert.Equal(t, MaxCallDepth+1, calls)
+}
@@ -0,0 +1,19 @@
+package expression
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+const FuncNameUUID = "uuid"
+
+// uuid generates a random (version 4) UUID
+func uuid() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"mcp-system-control/approval"
 	"mcp-system-control/config"
+	"mcp-system-control/expression"
+	"mcp-system-control/expression/tester"
 	mcpServer "mcp-system-control/mcp/server"
+	cServer "mcp-system-control/mcp/server/custom"
 	"os"
 
 	"github.com/mark3labs/mcp-go/server"
@@ -17,6 +21,17 @@ func main() {
 		fmt.Fprintln(os.Stderr, versionLine())
 		os.Exit(0)
 	}
+	if cfg.TestExpression.Expression != "" {
+		if err := cfg.Expression.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		if err := tester.Run(context.Background(), os.Stdout, cfg.TestExpression); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
 	if err := cfg.Validate(); err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
@@ -24,14 +39,32 @@ func main() {
 	}
 	slog.SetLogLoggerLevel(*cfg.DebugConfig.LogLevelParsed)
 
+	if !cfg.Expression.DisableReload {
+		go expression.WatchPrecompiled(context.Background(), cfg.Expression.ReloadInterval)
+	}
+
+	approvalRequester := approval.NewRequester(cfg.Approval)
+	expression.SetApprovalRequester(approvalRequester)
+
 	ms := mcpServer.NewServer(
 		cfg.MCP.Name,
 		versionLine(),
 		cfg.BuiltIns,
 		cfg.Custom,
-		approval.NewRequester(cfg.Approval),
+		approvalRequester,
 	)
 
+	if cfg.CustomDir.Path != "" {
+		w := cServer.NewDirWatcher(ms, cfg.CustomDir.Path, cfg.ToolPolicy(), approvalRequester)
+		if err := w.Load(); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		if !cfg.CustomDir.DisableReload {
+			go w.Watch(context.Background(), cfg.CustomDir.ReloadInterval)
+		}
+	}
+
 	var err error
 
 	if cfg.MCP.SSE.BindAddress != nil {
@@ -40,7 +40,7 @@ func AddTools(s *server.MCPServer, cfg model.BuiltIns, approvalRequester approva
 					slog.Error("Failed to marshal arguments", "error", err)
 					return nil, fmt.Errorf("failed to marshal arguments")
 				}
-				if as.NeedsApproval(ctx, string(argsAsJson), nil) {
+				if as.NeedsApproval(ctx, string(argsAsJson), tool) {
 					approved, err := approvalRequester.WaitForApproval(ctx, &request)
 					if err != nil {
 						return nil, fmt.Errorf("error while waiting for approval: %w", err)
@@ -104,6 +104,6 @@ func AddTools(s *server.MCPServer, cfg model.BuiltIns, approvalRequester approva
 	}
 
 	if !cfg.CommandExec.Disable {
-		addTool(command.CommandExecutionTool, command.CommandExecutionToolHandler)
+		addTool(command.CommandExecutionTool, command.NewCommandExecutionToolHandler(cfg.CommandExec.Settings()))
 	}
 }
@@ -3,10 +3,16 @@ package command
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	cmdchain "github.com/rainu/go-command-chain"
 )
@@ -18,7 +24,76 @@ type CommandDescriptor struct {
 	Environment           map[string]string `json:"env"`
 	AdditionalEnvironment map[string]string `json:"additionalEnv"`
 	WorkingDirectory      string            `json:"workingDir"`
+	Stdin                 string            `json:"stdin,omitempty"`
+	TimeoutMs             int64             `json:"timeoutMs,omitempty"`
+	GracePeriodMs         int64             `json:"gracePeriodMs,omitempty"`
+	SuccessExitCodes      []int             `json:"successExitCodes,omitempty"`
+	FailOnExitCode        bool              `json:"failOnExitCode,omitempty"`
 	Output                *OutputSettings   `json:"output,omitempty"`
+
+	// Steps will be piped together (instead of CommandLine or Name and Arguments)
+	Steps []CommandStep `json:"steps,omitempty"`
+}
+
+// CommandStep is a command of a pipeline. It receives the stdout of its predecessor as stdin.
+type CommandStep struct {
+	Name      string   `json:"name"`
+	Arguments []string `json:"arguments,omitempty"`
+
+	// ContinueOnError ignores an unsuccessful exit code of this step
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+}
+
+// pipeline records the processes and the stderr (excerpts) of the steps.
+type pipeline struct {
+	cmds   []*exec.Cmd
+	stderr []*tailBuffer
+}
+
+func (p *pipeline) record(_ int, cmd *exec.Cmd) {
+	p.cmds = append(p.cmds, cmd)
+}
+
+// DefaultGracePeriod is the time between the termination signal (SIGTERM) and the kill of a command
+// which is canceled (e.g. because of a timeout).
+const DefaultGracePeriod = 5 * time.Second
+
+// stderrExcerptSize is the maximum number of bytes of the stderr which will be reported in an ExitError.
+const stderrExcerptSize = 1024
+
+// ErrTimeout is returned if the command does not finish within its timeout.
+var ErrTimeout = errors.New("command timed out")
+
+// ExitError is returned by Run if the command exits with an unsuccessful exit code
+// (only if CommandDescriptor.FailOnExitCode is set).
+type ExitError struct {
+	ExitCode int
+	// Stderr contains the last bytes of the command's stderr
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("command exited with code %d", e.ExitCode)
+	if e.Stderr != "" {
+		msg += "\nstderr:\n" + e.Stderr
+	}
+	return msg
+}
+
+// StepError is returned by Run if a step of the pipeline exits with an unsuccessful exit code.
+type StepError struct {
+	// Step is the number of the step (starting at 1)
+	Step int
+	Name string
+	Err  *ExitError
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step #%d (%s) failed: %s", e.Step, e.Name, e.Err.Error())
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
 }
 
 type OutputSettings struct {
@@ -28,15 +103,52 @@ type OutputSettings struct {
 	LastNBytes    int  `json:"lastNBytes"`
 }
 
-func (c CommandDescriptor) Run(ctx context.Context) ([]byte, error) {
+type ExecutionResult struct {
+	ExitCode   int    `json:"exitCode"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMs int64  `json:"durationMs"`
+	Truncated  bool   `json:"truncated"`
+}
+
+func (c CommandDescriptor) builder(ctx context.Context) (cmdchain.CommandBuilder, *pipeline) {
+	var chainBuild cmdchain.ChainBuilder = cmdchain.Builder()
+	if c.Stdin != "" {
+		chainBuild = cmdchain.Builder().WithInput(strings.NewReader(c.Stdin))
+	}
+
 	var cmdBuild cmdchain.CommandBuilder
+	if len(c.Steps) > 0 {
+		p := &pipeline{}
+		for i, step := range c.Steps {
+			if i == 0 {
+				cmdBuild = chainBuild.JoinWithContext(ctx, step.Name, step.Arguments...)
+			} else {
+				cmdBuild = cmdBuild.JoinWithContext(ctx, step.Name, step.Arguments...)
+			}
+			cmdBuild = c.configure(cmdBuild).Apply(p.record)
+
+			if i < len(c.Steps)-1 {
+				// the stderr of the last step will be handled by the caller
+				stderr := &tailBuffer{size: stderrExcerptSize}
+				p.stderr = append(p.stderr, stderr)
+				cmdBuild = cmdBuild.WithErrorForks(stderr)
+			}
+		}
+		return cmdBuild, p
+	}
 
 	if c.CommandLine != "" {
-		cmdBuild = cmdchain.Builder().JoinShellCmdWithContext(ctx, c.CommandLine)
+		cmdBuild = chainBuild.JoinShellCmdWithContext(ctx, c.CommandLine)
 	} else {
-		cmdBuild = cmdchain.Builder().JoinWithContext(ctx, c.Name, c.Arguments...)
+		cmdBuild = chainBuild.JoinWithContext(ctx, c.Name, c.Arguments...)
 	}
 
+	return c.configure(cmdBuild), nil
+}
+
+// configure applies the environment, working directory and termination settings to the previously joined command.
+func (c CommandDescriptor) configure(cmdBuild cmdchain.CommandBuilder) cmdchain.CommandBuilder {
 	if len(c.Environment) > 0 {
 		cmdBuild = cmdBuild.WithEnvironmentMap(toAnyMap(c.Environment))
 	}
@@ -47,6 +159,99 @@ func (c CommandDescriptor) Run(ctx context.Context) ([]byte, error) {
 		cmdBuild = cmdBuild.WithWorkingDirectory(c.WorkingDirectory)
 	}
 
+	return cmdBuild.Apply(c.terminateGracefully)
+}
+
+// terminateGracefully lets the command terminate itself (SIGTERM) if the context is done.
+// If it is still running after the grace period, it will be killed. This only applies if a timeout
+// or grace period is configured, otherwise the command will be killed immediately.
+func (c CommandDescriptor) terminateGracefully(_ int, cmd *exec.Cmd) {
+	if c.GracePeriodMs < 0 || (c.GracePeriodMs == 0 && c.TimeoutMs <= 0) {
+		// kill immediately (default behavior of exec.CommandContext)
+		return
+	}
+
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = DefaultGracePeriod
+	if c.GracePeriodMs > 0 {
+		cmd.WaitDelay = time.Duration(c.GracePeriodMs) * time.Millisecond
+	}
+}
+
+func (c CommandDescriptor) timeoutError() error {
+	return fmt.Errorf("%w after %s", ErrTimeout, time.Duration(c.TimeoutMs)*time.Millisecond)
+}
+
+// checkSteps checks the exit codes of all steps which are not allowed to fail.
+func (c CommandDescriptor) checkSteps(p *pipeline, stderr *tailBuffer) error {
+	for i, cmd := range p.cmds {
+		if c.Steps[i].ContinueOnError || cmd.ProcessState == nil {
+			continue
+		}
+		last := i == len(p.cmds)-1
+		if !last && brokenPipe(cmd.ProcessState) {
+			// the successor has stopped reading (e.g. "head")
+			continue
+		}
+
+		if exitCode := cmd.ProcessState.ExitCode(); !c.isSuccess(exitCode) {
+			stepErr := &StepError{Step: i + 1, Name: c.Steps[i].Name, Err: &ExitError{ExitCode: exitCode}}
+			if last {
+				stepErr.Err.Stderr = stderr.String()
+			} else {
+				stepErr.Err.Stderr = p.stderr[i].String()
+			}
+			return stepErr
+		}
+	}
+	return nil
+}
+
+func brokenPipe(state *os.ProcessState) bool {
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	return ok && ws.Signaled() && ws.Signal() == syscall.SIGPIPE
+}
+
+// isSuccess checks if the given exit code is treated as success.
+func (c CommandDescriptor) isSuccess(exitCode int) bool {
+	if len(c.SuccessExitCodes) == 0 {
+		return exitCode == 0
+	}
+	return slices.Contains(c.SuccessExitCodes, exitCode)
+}
+
+// withTimeout applies the command's timeout to the context. The returned error is the cause of the context
+// if (and only if) the command's own timeout expires (not the one of the parent context).
+func (c CommandDescriptor) withTimeout(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if c.TimeoutMs > 0 {
+		timeoutErr := c.timeoutError()
+		ctx, cancel := context.WithTimeoutCause(ctx, time.Duration(c.TimeoutMs)*time.Millisecond, timeoutErr)
+		return ctx, cancel, timeoutErr
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, cancel, nil
+}
+
+// contextError returns the error if the command was stopped by its context.
+func contextError(ctx context.Context, timeoutErr error) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	cause := context.Cause(ctx)
+	if timeoutErr != nil && cause == timeoutErr {
+		return timeoutErr
+	}
+	return fmt.Errorf("command canceled: %w", cause)
+}
+
+func (c CommandDescriptor) Run(ctx context.Context) ([]byte, error) {
+	ctx, cancel, timeoutErr := c.withTimeout(ctx)
+	defer cancel()
+
+	cmdBuild, p := c.builder(ctx)
+
 	oFile, err := os.CreateTemp("", "mcp-system-control.mcp.command.*")
 	if err != nil {
 		return nil, fmt.Errorf("could not create temporary file: %w", err)
@@ -56,16 +261,145 @@ func (c CommandDescriptor) Run(ctx context.Context) ([]byte, error) {
 		os.Remove(oFile.Name())
 	}()
 
-	cmd := cmdBuild.WithErrorChecker(cmdchain.IgnoreExitErrors()).Finalize()
+	cmd := cmdBuild.Finalize()
+	if !c.FailOnExitCode || p != nil {
+		// the exit codes of the steps will be checked separately
+		cmd = cmd.WithGlobalErrorChecker(cmdchain.IgnoreExitErrors())
+	}
 	if c.Output == nil || !c.Output.DisableStdOut {
 		cmd = cmd.WithOutput(oFile)
 	}
+
+	// the stderr will always be recorded for the exit error
+	stderr := &tailBuffer{size: stderrExcerptSize}
 	if c.Output == nil || !c.Output.DisableStdErr {
-		cmd = cmd.WithError(oFile)
+		cmd = cmd.WithError(oFile, stderr)
+	} else {
+		cmd = cmd.WithError(stderr)
 	}
 
 	execErr := cmd.Run()
-	return c.getOutput(oFile), execErr
+	output := c.getOutput(oFile)
+
+	if err := contextError(ctx, timeoutErr); err != nil {
+		return output, err
+	}
+	if p != nil && execErr == nil {
+		return output, c.checkSteps(p, stderr)
+	}
+	if !c.FailOnExitCode {
+		return output, execErr
+	}
+
+	exitCode, err := exitCodeOf(execErr)
+	if err != nil {
+		return output, err
+	}
+	if !c.isSuccess(exitCode) {
+		return output, &ExitError{ExitCode: exitCode, Stderr: stderr.String()}
+	}
+	return output, nil
+}
+
+// Exec runs the command and returns a structured result. In contrast to Run, the stdout and stderr
+// will be kept separate and the exit code of the (last) command will be reported.
+func (c CommandDescriptor) Exec(ctx context.Context) (*ExecutionResult, error) {
+	ctx, cancel, timeoutErr := c.withTimeout(ctx)
+	defer cancel()
+
+	cmdBuild, _ := c.builder(ctx)
+
+	oFile, err := os.CreateTemp("", "mcp-system-control.mcp.command.out.*")
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary file: %w", err)
+	}
+	defer func() {
+		oFile.Close()
+		os.Remove(oFile.Name())
+	}()
+
+	eFile, err := os.CreateTemp("", "mcp-system-control.mcp.command.err.*")
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary file: %w", err)
+	}
+	defer func() {
+		eFile.Close()
+		os.Remove(eFile.Name())
+	}()
+
+	cmd := cmdBuild.Finalize()
+	if c.Output == nil || !c.Output.DisableStdOut {
+		cmd = cmd.WithOutput(oFile)
+	}
+	if c.Output == nil || !c.Output.DisableStdErr {
+		cmd = cmd.WithError(eFile)
+	}
+
+	start := time.Now()
+	execErr := cmd.Run()
+	result := &ExecutionResult{
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	if err := contextError(ctx, timeoutErr); err != nil {
+		return nil, err
+	}
+
+	result.ExitCode, err = exitCodeOf(execErr)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Stdout = string(c.getOutput(oFile))
+	result.Stderr = string(c.getOutput(eFile))
+	result.Truncated = c.isTruncated(oFile) || c.isTruncated(eFile)
+
+	return result, nil
+}
+
+// exitCodeOf returns the exit code of the last command in the chain. If the error is not caused
+// by the exit code of a command, the error will be returned.
+func exitCodeOf(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+
+	mErr, isMultiErr := err.(cmdchain.MultipleErrors)
+	if !isMultiErr || len(mErr.Errors()) == 0 {
+		return -1, err
+	}
+
+	errs := mErr.Errors()
+	if nested, isNested := errs[0].(cmdchain.MultipleErrors); isNested {
+		// run and stream errors occurred
+		return exitCodeOf(nested)
+	}
+
+	last := errs[len(errs)-1]
+	if last == nil {
+		return 0, nil
+	}
+	if errors.As(last, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	return -1, last
+}
+
+func (c CommandDescriptor) isTruncated(f *os.File) bool {
+	if c.Output == nil || c.Output.FirstNBytes < 0 || c.Output.LastNBytes < 0 {
+		return false
+	}
+
+	fs, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return int64(c.Output.FirstNBytes+c.Output.LastNBytes) < fs.Size()
 }
 
 func (c CommandDescriptor) getOutput(f *os.File) []byte {
@@ -166,6 +500,24 @@ func readFile(f *os.File) []byte {
 	return content
 }
 
+// tailBuffer keeps only the last bytes which are written into it.
+type tailBuffer struct {
+	size int
+	buf  []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.size {
+		t.buf = t.buf[len(t.buf)-t.size:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
+
 func toAnyMap(m map[string]string) map[any]any {
 	result := map[any]any{}
 	for k, v := range m {
@@ -1,9 +1,14 @@
 package command
 
 import (
+	"context"
 	"os"
+	"os/exec"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -79,3 +84,272 @@ func Test_getOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandDescriptor_Exec(t *testing.T) {
+	res, err := CommandDescriptor{
+		CommandLine: `sh -c 'cat; echo "error" >&2; exit 3'`,
+		Stdin:       "hello world",
+	}.Exec(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.ExitCode)
+	assert.Equal(t, "hello world", res.Stdout)
+	assert.Equal(t, "error\n", res.Stderr)
+	assert.False(t, res.Truncated)
+}
+
+func TestCommandDescriptor_Exec_Truncated(t *testing.T) {
+	res, err := CommandDescriptor{
+		Name:      "echo",
+		Arguments: []string{"hello world"},
+		Output:    &OutputSettings{FirstNBytes: 5},
+	}.Exec(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.ExitCode)
+	assert.Equal(t, "hello\n{{ 7 bytes skipped }}", res.Stdout)
+	assert.True(t, res.Truncated)
+}
+
+func TestCommandDescriptor_Exec_Timeout(t *testing.T) {
+	_, err := CommandDescriptor{
+		Name:      "sleep",
+		Arguments: []string{"10"},
+		TimeoutMs: 100,
+	}.Exec(t.Context())
+
+	assert.EqualError(t, err, "command timed out after 100ms")
+}
+
+func TestCommandDescriptor_Exec_Unknown(t *testing.T) {
+	_, err := CommandDescriptor{
+		Name: "CommandShouldNotExists",
+	}.Exec(t.Context())
+
+	assert.Error(t, err)
+}
+
+func TestCommandDescriptor_Run_IgnoreExitCode(t *testing.T) {
+	output, err := CommandDescriptor{
+		CommandLine: `sh -c 'echo "hello"; exit 3'`,
+	}.Run(t.Context())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\n", string(output))
+}
+
+func TestCommandDescriptor_Run_FailOnExitCode(t *testing.T) {
+	output, err := CommandDescriptor{
+		CommandLine:    `sh -c 'echo "hello"; echo "error" >&2; exit 3'`,
+		FailOnExitCode: true,
+	}.Run(t.Context())
+
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode)
+	assert.Equal(t, "error\n", exitErr.Stderr)
+	assert.EqualError(t, err, "command exited with code 3\nstderr:\nerror\n")
+	assert.Equal(t, "hello\nerror\n", string(output))
+}
+
+func TestCommandDescriptor_Run_FailOnExitCode_StderrExcerpt(t *testing.T) {
+	_, err := CommandDescriptor{
+		CommandLine:    `sh -c 'head -c 2000 /dev/zero | tr "\0" "a" >&2; echo "end" >&2; exit 1'`,
+		Output:         &OutputSettings{DisableStdErr: true},
+		FailOnExitCode: true,
+	}.Run(t.Context())
+
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Len(t, exitErr.Stderr, stderrExcerptSize)
+	assert.True(t, strings.HasSuffix(exitErr.Stderr, "aaaend\n"))
+}
+
+func TestCommandDescriptor_Run_SuccessExitCodes(t *testing.T) {
+	desc := CommandDescriptor{
+		CommandLine:      `sh -c 'exit 1'`,
+		SuccessExitCodes: []int{0, 1},
+		FailOnExitCode:   true,
+	}
+	_, err := desc.Run(t.Context())
+	assert.NoError(t, err)
+
+	desc.CommandLine = `sh -c 'exit 0'`
+	_, err = desc.Run(t.Context())
+	assert.NoError(t, err)
+
+	desc.CommandLine = `sh -c 'exit 2'`
+	_, err = desc.Run(t.Context())
+	assert.EqualError(t, err, "command exited with code 2")
+}
+
+func TestCommandDescriptor_Run_Timeout(t *testing.T) {
+	_, err := CommandDescriptor{
+		Name:      "sleep",
+		Arguments: []string{"10"},
+		TimeoutMs: 100,
+	}.Run(t.Context())
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.EqualError(t, err, "command timed out after 100ms")
+}
+
+func TestCommandDescriptor_Run_Timeout_Graceful(t *testing.T) {
+	output, err := CommandDescriptor{
+		CommandLine: `sh -c 'trap "echo terminated; exit 1" TERM; while true; do sleep 0.01; done'`,
+		TimeoutMs:   200,
+	}.Run(t.Context())
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, "terminated\n", string(output))
+}
+
+func TestCommandDescriptor_Run_Timeout_GracePeriod(t *testing.T) {
+	start := time.Now()
+	_, err := CommandDescriptor{
+		CommandLine:   `sh -c 'trap "" TERM; sleep 10'`,
+		TimeoutMs:     100,
+		GracePeriodMs: 200,
+	}.Run(t.Context())
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestCommandDescriptor_Run_ParentTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := CommandDescriptor{
+		Name:      "sleep",
+		Arguments: []string{"10"},
+	}.Run(ctx)
+
+	assert.NotErrorIs(t, err, ErrTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, "command canceled: context deadline exceeded")
+	assert.Less(t, time.Since(start), DefaultGracePeriod)
+}
+
+func TestCommandDescriptor_Exec_ParentTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := CommandDescriptor{
+		Name:      "sleep",
+		Arguments: []string{"10"},
+		TimeoutMs: 5000,
+	}.Exec(ctx)
+
+	assert.NotErrorIs(t, err, ErrTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCommandDescriptor_terminateGracefully(t *testing.T) {
+	tests := []struct {
+		name      string
+		desc      CommandDescriptor
+		graceful  bool
+		waitDelay time.Duration
+	}{
+		{name: "nothing configured", desc: CommandDescriptor{}},
+		{name: "timeout", desc: CommandDescriptor{TimeoutMs: 100}, graceful: true, waitDelay: DefaultGracePeriod},
+		{name: "grace period", desc: CommandDescriptor{GracePeriodMs: 100}, graceful: true, waitDelay: 100 * time.Millisecond},
+		{name: "kill immediately", desc: CommandDescriptor{TimeoutMs: 100, GracePeriodMs: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.CommandContext(t.Context(), "true")
+			tt.desc.terminateGracefully(0, cmd)
+
+			assert.Equal(t, tt.waitDelay, cmd.WaitDelay)
+			if tt.graceful {
+				assert.NotNil(t, cmd.Cancel)
+			}
+		})
+	}
+}
+
+func TestCommandDescriptor_Run_Steps(t *testing.T) {
+	output, err := CommandDescriptor{
+		Stdin: "b\na\nc\na\n",
+		Steps: []CommandStep{
+			{Name: "sort"},
+			{Name: "uniq", Arguments: []string{"-c"}},
+			{Name: "wc", Arguments: []string{"-l"}},
+		},
+	}.Run(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, "3", strings.TrimSpace(string(output)))
+}
+
+func TestCommandDescriptor_Run_Steps_Failure(t *testing.T) {
+	output, err := CommandDescriptor{
+		Steps: []CommandStep{
+			{Name: "sh", Arguments: []string{"-c", `echo "hello"; echo "error" >&2; exit 2`}},
+			{Name: "cat"},
+		},
+	}.Run(t.Context())
+
+	var stepErr *StepError
+	require.ErrorAs(t, err, &stepErr)
+	assert.Equal(t, 1, stepErr.Step)
+	assert.Equal(t, "sh", stepErr.Name)
+	assert.EqualError(t, err, "step #1 (sh) failed: command exited with code 2\nstderr:\nerror\n")
+	assert.Equal(t, "hello\n", string(output))
+
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 2, exitErr.ExitCode)
+}
+
+func TestCommandDescriptor_Run_Steps_LastFailure(t *testing.T) {
+	_, err := CommandDescriptor{
+		Stdin: "hello",
+		Steps: []CommandStep{
+			{Name: "cat"},
+			{Name: "grep", Arguments: []string{"world"}},
+		},
+	}.Run(t.Context())
+
+	assert.EqualError(t, err, "step #2 (grep) failed: command exited with code 1")
+}
+
+func TestCommandDescriptor_Run_Steps_ContinueOnError(t *testing.T) {
+	output, err := CommandDescriptor{
+		Steps: []CommandStep{
+			{Name: "sh", Arguments: []string{"-c", `echo "hello"; exit 2`}, ContinueOnError: true},
+			{Name: "cat"},
+		},
+	}.Run(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(output))
+}
+
+func TestCommandDescriptor_Run_Steps_BrokenPipe(t *testing.T) {
+	output, err := CommandDescriptor{
+		Steps: []CommandStep{
+			{Name: "yes"},
+			{Name: "head", Arguments: []string{"-n", "2"}},
+		},
+	}.Run(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, "y\ny\n", string(output))
+}
+
+func TestCommandDescriptor_Run_Steps_SuccessExitCodes(t *testing.T) {
+	_, err := CommandDescriptor{
+		Stdin: "hello",
+		Steps: []CommandStep{
+			{Name: "cat"},
+			{Name: "grep", Arguments: []string{"world"}},
+		},
+		SuccessExitCodes: []int{0, 1},
+	}.Run(t.Context())
+
+	assert.NoError(t, err)
+}
@@ -3,10 +3,12 @@ package command
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 type CommandExecutionArguments struct {
@@ -27,6 +29,11 @@ var defaultCommandExecutionArguments = CommandExecutionArguments{
 
 var CommandExecutionTool = mcp.NewTool("executeCommand",
 	mcp.WithDescription(`Execute a command on the user's system. Concatenations with binary operators like "&&" or "||" are not supported.`),
+	mcp.WithTitleAnnotation("Execute command"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithIdempotentHintAnnotation(false),
+	mcp.WithOpenWorldHintAnnotation(true),
 	mcp.WithString("command",
 		mcp.Required(),
 		mcp.Description("The shell-like command to execute."),
@@ -56,7 +63,17 @@ var CommandExecutionTool = mcp.NewTool("executeCommand",
 	),
 )
 
-var CommandExecutionToolHandler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+var CommandExecutionToolHandler = NewCommandExecutionToolHandler(CommandDescriptor{})
+
+// NewCommandExecutionToolHandler creates a handler for the command execution tool. The timeout and exit code
+// settings of the given descriptor will be applied to each executed command.
+func NewCommandExecutionToolHandler(settings CommandDescriptor) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleCommandExecution(ctx, request, settings)
+	}
+}
+
+func handleCommandExecution(ctx context.Context, request mcp.CallToolRequest, settings CommandDescriptor) (*mcp.CallToolResult, error) {
 	pArgs := defaultCommandExecutionArguments
 
 	r, w := io.Pipe()
@@ -79,6 +96,10 @@ var CommandExecutionToolHandler = func(ctx context.Context, request mcp.CallTool
 		CommandLine:           pArgs.Command,
 		AdditionalEnvironment: pArgs.Environment,
 		WorkingDirectory:      pArgs.WorkingDirectory,
+		TimeoutMs:             settings.TimeoutMs,
+		GracePeriodMs:         settings.GracePeriodMs,
+		SuccessExitCodes:      settings.SuccessExitCodes,
+		FailOnExitCode:        settings.FailOnExitCode,
 		Output: &OutputSettings{
 			DisableStdOut: pArgs.DisableOut,
 			DisableStdErr: pArgs.DisableErr,
@@ -87,6 +108,15 @@ var CommandExecutionToolHandler = func(ctx context.Context, request mcp.CallTool
 		},
 	}
 
-	raw, err := cmdDesc.Run(ctx)
-	return mcp.NewToolResultText(string(raw)), err
+	return ToolResult(cmdDesc.Run(ctx))
+}
+
+// ToolResult converts the output of CommandDescriptor.Run into a tool result. Unsuccessful exits and timeouts
+// will be reported as error result, so that the LLM is aware of the failure.
+func ToolResult(output []byte, err error) (*mcp.CallToolResult, error) {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) || errors.Is(err, ErrTimeout) {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(output)), err
 }
@@ -103,13 +103,51 @@ func TestTool_Command_Exec_Unknown(t *testing.T) {
 	assert.Nil(t, res)
 }
 
+func TestTool_Command_Exec_FailOnExitCode(t *testing.T) {
+	c := getTestClientWith(t, NewCommandExecutionToolHandler(CommandDescriptor{
+		FailOnExitCode: true,
+	}))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = CommandExecutionTool.Name
+	req.Params.Arguments = map[string]any{
+		"command": `sh -c 'echo "error" >&2; exit 3'`,
+	}
+
+	res, err := c.CallTool(t.Context(), req)
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.Equal(t, "command exited with code 3\nstderr:\nerror\n", res.Content[0].(mcp.TextContent).Text)
+}
+
+func TestTool_Command_Exec_Timeout(t *testing.T) {
+	c := getTestClientWith(t, NewCommandExecutionToolHandler(CommandDescriptor{
+		TimeoutMs: 100,
+	}))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = CommandExecutionTool.Name
+	req.Params.Arguments = map[string]any{
+		"command": "sleep 10",
+	}
+
+	res, err := c.CallTool(t.Context(), req)
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.Equal(t, "command timed out after 100ms", res.Content[0].(mcp.TextContent).Text)
+}
+
 func getTestClient(t *testing.T) *client.Client {
+	return getTestClientWith(t, CommandExecutionToolHandler)
+}
+
+func getTestClientWith(t *testing.T, handler server.ToolHandlerFunc) *client.Client {
 	s := server.NewMCPServer(
 		"mcp-system-control",
 		"test-version",
 		server.WithToolCapabilities(false),
 	)
-	s.AddTool(CommandExecutionTool, CommandExecutionToolHandler)
+	s.AddTool(CommandExecutionTool, handler)
 
 	c := client.NewClient(transport.NewInProcessTransport(s))
 
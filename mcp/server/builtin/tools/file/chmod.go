@@ -20,6 +20,11 @@ type ChangeModeResult struct {
 
 var ChangeModeTool = mcp.NewTool("changeMode",
 	mcp.WithDescription("Changes the mode of file or directory on the user's system."),
+	mcp.WithTitleAnnotation("Change file mode"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The path to the file or directory to change the mode for. Use '~' as placeholder for the user's home directory."),
@@ -22,6 +22,11 @@ type ChangeOwnerResult struct {
 
 var ChangeOwnerTool = mcp.NewTool("changeOwner",
 	mcp.WithDescription("Changes the owner of file or directory on the user's system. Does not work on 'Windows' or 'Plan 9' operating systems."),
+	mcp.WithTitleAnnotation("Change file owner"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The path to the file or directory to change the owner for. Use '~' as placeholder for the user's home directory."),
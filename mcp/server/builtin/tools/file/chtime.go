@@ -35,6 +35,11 @@ type ChangeTimesResult struct {
 
 var ChangeTimesTool = mcp.NewTool("changeTimes",
 	mcp.WithDescription("Changes the access and/or modification time of a file or directory on the user's system."),
+	mcp.WithTitleAnnotation("Change file times"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The path to the file or directory to change the times for. Use '~' as placeholder for the user's home directory."),
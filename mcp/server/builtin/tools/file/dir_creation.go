@@ -23,6 +23,11 @@ type DirectoryCreationResult struct {
 
 var DirectoryCreationTool = mcp.NewTool("createDirectory",
 	mcp.WithDescription("Creates a new directory (including all missing parent directories) on the user's system."),
+	mcp.WithTitleAnnotation("Create directory"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The path to the directory to create. Use '~' as placeholder for the user's home directory."),
@@ -22,6 +22,11 @@ type DirectoryDeletionResult struct {
 
 var DirectoryDeletionTool = mcp.NewTool("deleteDirectory",
 	mcp.WithDescription("Delete a directory (including all files and subdirectories) on the user's system."),
+	mcp.WithTitleAnnotation("Delete directory"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The path to the directory to delete. Use '~' as placeholder for the user's home directory."),
@@ -19,6 +19,11 @@ type DirectoryTempCreationResult struct {
 
 var DirectoryTempCreationTool = mcp.NewTool("createTempDirectory",
 	mcp.WithDescription("Creates a new temporary directory on the user's system."),
+	mcp.WithTitleAnnotation("Create temporary directory"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(false),
+	mcp.WithOpenWorldHintAnnotation(false),
 )
 
 var DirectoryTempCreationToolHandler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
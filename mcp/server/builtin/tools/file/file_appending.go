@@ -24,6 +24,11 @@ type FileAppendingResult struct {
 
 var FileAppendingTool = mcp.NewTool("appendFile",
 	mcp.WithDescription("Append content to an existing file on the user's system."),
+	mcp.WithTitleAnnotation("Append to file"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(false),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The path to the file to create. Use '~' as placeholder for the user's home directory."),
@@ -25,6 +25,11 @@ type FileCreationResult struct {
 
 var FileCreationTool = mcp.NewTool("createFile",
 	mcp.WithDescription("Creates a new file on the user's system."),
+	mcp.WithTitleAnnotation("Create file"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(false),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The path to the file to create. Use '~' as placeholder for the user's home directory."),
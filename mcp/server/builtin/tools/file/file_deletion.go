@@ -22,6 +22,11 @@ type FileDeletionResult struct {
 
 var FileDeletionTool = mcp.NewTool("deleteFile",
 	mcp.WithDescription("Delete a file on the user's system."),
+	mcp.WithTitleAnnotation("Delete file"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The path to the file to delete. Use '~' as placeholder for the user's home directory."),
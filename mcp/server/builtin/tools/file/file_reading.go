@@ -77,6 +77,11 @@ func readAll(file *os.File) (string, error) {
 
 var FileReadingTool = mcp.NewTool("readTextFile",
 	mcp.WithDescription("Read a text file from the user's system."),
+	mcp.WithTitleAnnotation("Read text file"),
+	mcp.WithReadOnlyHintAnnotation(true),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The absolute path to the text file to be read. Use '~' as placeholder for the user's home directory."),
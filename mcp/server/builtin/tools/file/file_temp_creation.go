@@ -25,6 +25,11 @@ type FileTempCreationResult struct {
 
 var FileTempCreationTool = mcp.NewTool("createTempFile",
 	mcp.WithDescription("Creates a new temporary file on the user's system."),
+	mcp.WithTitleAnnotation("Create temporary file"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(false),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("content",
 		mcp.Description("The content of the file."),
 	),
@@ -28,6 +28,11 @@ type StatsResult struct {
 
 var StatsTool = mcp.NewTool("getStats",
 	mcp.WithDescription("Get stats of a file or directory on the user's system."),
+	mcp.WithTitleAnnotation("Get file stats"),
+	mcp.WithReadOnlyHintAnnotation(true),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 	mcp.WithString("path",
 		mcp.Required(),
 		mcp.Description("The path to the file or directory to get info for. Use '~' as placeholder for the user's home directory."),
@@ -1,19 +1,61 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type CallDescriptor struct {
 	Method     string            `json:"method"`
 	Url        string            `json:"url"`
+	Query      map[string]any    `json:"query,omitempty"`
 	Header     map[string]string `json:"header"`
 	Body       io.Reader         `json:"-"`
 	StringBody string            `json:"body"`
+	Base64Body string            `json:"bodyBase64,omitempty"`
+	Form       map[string]any    `json:"form,omitempty"`
+	Multipart  []MultipartField  `json:"multipart,omitempty"`
+
+	TimeoutMs        int64        `json:"timeoutMs,omitempty"`
+	Redirect         *Redirect    `json:"redirect,omitempty"`
+	TLS              *TLSSettings `json:"tls,omitempty"`
+	MaxResponseBytes int64        `json:"maxResponseBytes,omitempty"`
+}
+
+type MultipartField struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	Base64Value string `json:"valueBase64,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type Redirect struct {
+	// Disable prevents following any redirect. The redirect response itself will be returned.
+	Disable bool `json:"disable,omitempty"`
+	// Max is the maximum number of redirects to follow. Zero means the default of the http client (10).
+	Max int `json:"max,omitempty"`
+}
+
+type TLSSettings struct {
+	// CA, ClientCert and ClientKey can either be a path to a PEM file or the PEM content itself.
+	CA                 string `json:"ca,omitempty"`
+	ClientCert         string `json:"clientCert,omitempty"`
+	ClientKey          string `json:"clientKey,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
 }
 
 type CallResult struct {
@@ -21,18 +63,43 @@ type CallResult struct {
 	Status     string              `json:"status"`
 	Header     map[string][]string `json:"header"`
 	Body       string              `json:"body"`
+	BodyBase64 string              `json:"bodyBase64,omitempty"`
+	Truncated  bool                `json:"truncated,omitempty"`
 }
 
 func (c *CallDescriptor) Run(ctx context.Context, client *http.Client) (*CallResult, error) {
-	if c.StringBody != "" {
-		c.Body = io.NopCloser(strings.NewReader(c.StringBody))
+	if c.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	client, err := c.clientFor(client)
+	if err != nil {
+		return nil, err
+	}
+
+	reqUrl, err := c.requestUrl()
+	if err != nil {
+		return nil, err
+	}
+
+	body, contentType, err := c.requestBody()
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		c.Body = body
 	}
 
-	req, err := http.NewRequestWithContext(ctx, c.Method, c.Url, c.Body)
+	req, err := http.NewRequestWithContext(ctx, c.Method, reqUrl, c.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
 	for key, value := range c.Header {
 		req.Header.Set(key, value)
 	}
@@ -48,11 +115,279 @@ func (c *CallDescriptor) Run(ctx context.Context, client *http.Client) (*CallRes
 	result.Status = resp.Status
 	result.Header = resp.Header
 
-	rawBody, err := io.ReadAll(resp.Body)
+	var bodyReader io.Reader = resp.Body
+	if c.MaxResponseBytes > 0 {
+		// read one more byte to detect if the body was truncated
+		bodyReader = io.LimitReader(resp.Body, c.MaxResponseBytes+1)
+	}
+
+	rawBody, err := io.ReadAll(bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
-	result.Body = string(rawBody)
+	if c.MaxResponseBytes > 0 && int64(len(rawBody)) > c.MaxResponseBytes {
+		rawBody = rawBody[:c.MaxResponseBytes]
+		result.Truncated = true
+	}
+
+	textBody := rawBody
+	if result.Truncated {
+		// the truncation may have cut a (multibyte) character of a text body
+		textBody = trimIncompleteRune(rawBody)
+	}
+
+	if isBinary(resp.Header.Get("Content-Type"), textBody) {
+		result.BodyBase64 = base64.StdEncoding.EncodeToString(rawBody)
+	} else {
+		result.Body = string(textBody)
+	}
 
 	return result, err
 }
+
+func (c *CallDescriptor) requestUrl() (string, error) {
+	if len(c.Query) == 0 {
+		return c.Url, nil
+	}
+
+	u, err := url.Parse(c.Url)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+
+	q := u.Query()
+	addValues(q, c.Query)
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+func (c *CallDescriptor) requestBody() (io.Reader, string, error) {
+	set := 0
+	for _, isSet := range []bool{c.StringBody != "", c.Base64Body != "", len(c.Form) > 0, len(c.Multipart) > 0} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, "", fmt.Errorf("only one of body, bodyBase64, form and multipart can be set")
+	}
+
+	switch {
+	case c.StringBody != "":
+		return strings.NewReader(c.StringBody), "", nil
+	case c.Base64Body != "":
+		raw, err := base64.StdEncoding.DecodeString(c.Base64Body)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid base64 body: %w", err)
+		}
+		return bytes.NewReader(raw), "", nil
+	case len(c.Form) > 0:
+		values := url.Values{}
+		addValues(values, c.Form)
+		return strings.NewReader(values.Encode()), "application/x-www-form-urlencoded", nil
+	case len(c.Multipart) > 0:
+		return multipartBody(c.Multipart)
+	}
+
+	return nil, "", nil
+}
+
+func multipartBody(fields []MultipartField) (io.Reader, string, error) {
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+
+	for _, field := range fields {
+		value := []byte(field.Value)
+		if field.Base64Value != "" {
+			var err error
+			value, err = base64.StdEncoding.DecodeString(field.Base64Value)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid base64 value for multipart field '%s': %w", field.Name, err)
+			}
+		}
+
+		var w io.Writer
+		var err error
+		if field.FileName == "" && field.ContentType == "" {
+			w, err = mw.CreateFormField(field.Name)
+		} else {
+			header := make(map[string][]string)
+			disposition := map[string]string{"name": field.Name}
+			if field.FileName != "" {
+				disposition["filename"] = field.FileName
+			}
+			header["Content-Disposition"] = []string{mime.FormatMediaType("form-data", disposition)}
+
+			contentType := field.ContentType
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			header["Content-Type"] = []string{contentType}
+
+			w, err = mw.CreatePart(header)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create multipart field '%s': %w", field.Name, err)
+		}
+		if _, err = w.Write(value); err != nil {
+			return nil, "", fmt.Errorf("failed to write multipart field '%s': %w", field.Name, err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finish multipart body: %w", err)
+	}
+	return buf, mw.FormDataContentType(), nil
+}
+
+// addValues adds the given values to the url values. A value can be a single value or a list of values.
+func addValues(target url.Values, values map[string]any) {
+	for key, value := range values {
+		switch v := value.(type) {
+		case []any:
+			for _, e := range v {
+				target.Add(key, fmt.Sprint(e))
+			}
+		case []string:
+			for _, e := range v {
+				target.Add(key, e)
+			}
+		default:
+			target.Add(key, fmt.Sprint(v))
+		}
+	}
+}
+
+// clientFor returns a client which respects the call specific settings (redirect, tls). The cookie jar
+// of the given client will be reused.
+func (c *CallDescriptor) clientFor(base *http.Client) (*http.Client, error) {
+	if c.Redirect == nil && c.TLS == nil {
+		return base, nil
+	}
+
+	client := *base
+
+	if c.Redirect != nil {
+		redirect := *c.Redirect
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if redirect.Disable {
+				return http.ErrUseLastResponse
+			}
+			max := redirect.Max
+			if max <= 0 {
+				max = 10
+			}
+			if len(via) >= max {
+				return fmt.Errorf("stopped after %d redirects", max)
+			}
+			return nil
+		}
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.config()
+		if err != nil {
+			return nil, err
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if bt, ok := base.Transport.(*http.Transport); ok && bt != nil {
+			transport = bt.Clone()
+		}
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	return &client, nil
+}
+
+func (t *TLSSettings) config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CA != "" {
+		ca, err := pemContent(t.CA)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no valid certificate found in ca")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.ClientCert != "" || t.ClientKey != "" {
+		if t.ClientCert == "" || t.ClientKey == "" {
+			return nil, fmt.Errorf("client certificate and key must be set together")
+		}
+		cert, err := pemContent(t.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client certificate: %w", err)
+		}
+		key, err := pemContent(t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client key: %w", err)
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+
+	return cfg, nil
+}
+
+// pemContent returns the given value if it is already PEM content. Otherwise, the value is treated as file path.
+func pemContent(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return []byte(value), nil
+	}
+	content, err := os.ReadFile(value)
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// trimIncompleteRune removes an incomplete UTF-8 character at the end of the body.
+func trimIncompleteRune(body []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(body); i++ {
+		start := len(body) - i
+		if utf8.RuneStart(body[start]) {
+			if utf8.FullRune(body[start:]) {
+				return body
+			}
+			return body[:start]
+		}
+	}
+	return body
+}
+
+func isBinary(contentType string, body []byte) bool {
+	if !utf8.Valid(body) {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range []string{"image/", "audio/", "video/", "font/"} {
+		if strings.HasPrefix(mediaType, prefix) && mediaType != "image/svg+xml" {
+			return true
+		}
+	}
+	switch mediaType {
+	case "application/octet-stream", "application/pdf", "application/zip", "application/gzip":
+		return true
+	}
+
+	return false
+}
@@ -2,13 +2,19 @@ package http
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/pem"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCallDescriptor_Run(t *testing.T) {
@@ -54,3 +60,235 @@ func TestCallDescriptor_Run(t *testing.T) {
 		Body: `{"message":"Success"}`,
 	}, result)
 }
+
+func TestCallDescriptor_Run_QueryAndForm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{"1", "2"}, r.URL.Query()["id"])
+		assert.Equal(t, "bar", r.URL.Query().Get("foo"))
+		assert.Equal(t, "keep", r.URL.Query().Get("existing"))
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "value", r.PostForm.Get("field"))
+
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	callDescriptor := CallDescriptor{
+		Method: http.MethodPost,
+		Url:    server.URL + "?existing=keep",
+		Query: map[string]any{
+			"id":  []any{1, 2},
+			"foo": "bar",
+		},
+		Form: map[string]any{"field": "value"},
+	}
+
+	result, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", result.Body)
+}
+
+func TestCallDescriptor_Run_Multipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1024))
+		assert.Equal(t, "value", r.MultipartForm.Value["field"][0])
+
+		fh := r.MultipartForm.File["file"][0]
+		assert.Equal(t, "test.bin", fh.Filename)
+		f, err := fh.Open()
+		require.NoError(t, err)
+		content, _ := io.ReadAll(f)
+		assert.Equal(t, []byte{0x00, 0xFF}, content)
+	}))
+	defer server.Close()
+
+	callDescriptor := CallDescriptor{
+		Method: http.MethodPost,
+		Url:    server.URL,
+		Multipart: []MultipartField{
+			{Name: "field", Value: "value"},
+			{Name: "file", FileName: "test.bin", Base64Value: base64.StdEncoding.EncodeToString([]byte{0x00, 0xFF})},
+		},
+	}
+
+	_, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+}
+
+func TestCallDescriptor_Run_BinaryBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, []byte{0x00, 0xFF}, body)
+
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte{0x89, 0x50, 0x4E, 0x47})
+	}))
+	defer server.Close()
+
+	callDescriptor := CallDescriptor{
+		Method:     http.MethodPost,
+		Url:        server.URL,
+		Base64Body: base64.StdEncoding.EncodeToString([]byte{0x00, 0xFF}),
+	}
+
+	result, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, "", result.Body)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0x89, 0x50, 0x4E, 0x47}), result.BodyBase64)
+}
+
+func TestCallDescriptor_Run_AmbiguousBody(t *testing.T) {
+	callDescriptor := CallDescriptor{
+		Method:     http.MethodPost,
+		Url:        "http://localhost",
+		StringBody: "body",
+		Form:       map[string]any{"field": "value"},
+	}
+
+	_, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	assert.EqualError(t, err, "only one of body, bodyBase64, form and multipart can be set")
+}
+
+func TestCallDescriptor_Run_MaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	callDescriptor := CallDescriptor{
+		Method:           http.MethodGet,
+		Url:              server.URL,
+		MaxResponseBytes: 5,
+	}
+
+	result, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result.Body)
+	assert.True(t, result.Truncated)
+}
+
+func TestCallDescriptor_Run_MaxResponseBytes_Utf8(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "Jürgen"}`))
+	}))
+	defer server.Close()
+
+	callDescriptor := CallDescriptor{
+		Method:           http.MethodGet,
+		Url:              server.URL,
+		MaxResponseBytes: 12,
+	}
+
+	result, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name": "J`, result.Body)
+	assert.Empty(t, result.BodyBase64)
+	assert.True(t, result.Truncated)
+}
+
+func TestCallDescriptor_Run_MaxResponseBytes_Binary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0xff, 0xfe, 0x00, 0x01, 0xc3})
+	}))
+	defer server.Close()
+
+	callDescriptor := CallDescriptor{
+		Method:           http.MethodGet,
+		Url:              server.URL,
+		MaxResponseBytes: 4,
+	}
+
+	result, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe, 0x00, 0x01}), result.BodyBase64)
+	assert.True(t, result.Truncated)
+}
+
+func TestCallDescriptor_Run_Redirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	}))
+	defer server.Close()
+
+	callDescriptor := CallDescriptor{
+		Method:   http.MethodGet,
+		Url:      server.URL,
+		Redirect: &Redirect{Disable: true},
+	}
+
+	result, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+
+	callDescriptor.Redirect = &Redirect{Max: 2}
+	_, err = callDescriptor.Run(context.Background(), http.DefaultClient)
+	assert.ErrorContains(t, err, "stopped after 2 redirects")
+}
+
+func TestCallDescriptor_Run_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	callDescriptor := CallDescriptor{
+		Method:    http.MethodGet,
+		Url:       server.URL,
+		TimeoutMs: 50,
+	}
+
+	_, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCallDescriptor_Run_CustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	callDescriptor := CallDescriptor{
+		Method: http.MethodGet,
+		Url:    server.URL,
+	}
+
+	_, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	assert.Error(t, err, "the test certificate should not be trusted by default")
+
+	callDescriptor.TLS = &TLSSettings{
+		CA: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+	}
+	result, err := callDescriptor.Run(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", result.Body)
+}
+
+func TestClientFor(t *testing.T) {
+	assert.Same(t, DefaultClient, ClientFor(context.Background()))
+
+	s := server.NewMCPServer("test", "test")
+	ctx1 := s.WithContext(context.Background(), testSession{id: "1"})
+	ctx2 := s.WithContext(context.Background(), testSession{id: "2"})
+
+	c1 := ClientFor(ctx1)
+	assert.Same(t, c1, ClientFor(ctx1))
+	assert.NotSame(t, c1, ClientFor(ctx2))
+	assert.NotSame(t, c1.Jar, ClientFor(ctx2).Jar)
+
+	ReleaseSession("1")
+	assert.NotSame(t, c1, ClientFor(ctx1))
+}
+
+type testSession struct {
+	id string
+}
+
+func (t testSession) Initialize()                                         {}
+func (t testSession) Initialized() bool                                   { return true }
+func (t testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (t testSession) SessionID() string                                   { return t.id }
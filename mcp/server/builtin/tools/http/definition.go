@@ -28,6 +28,11 @@ func init() {
 
 var CallTool = mcp.NewTool("callHttp",
 	mcp.WithDescription("Do a http call to a given url with a given method and body."),
+	mcp.WithTitleAnnotation("Call http endpoint"),
+	mcp.WithReadOnlyHintAnnotation(false),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithIdempotentHintAnnotation(false),
+	mcp.WithOpenWorldHintAnnotation(true),
 	mcp.WithString("method",
 		mcp.Enum(
 			http.MethodGet,
@@ -77,7 +82,7 @@ var CallToolHandler = func(ctx context.Context, request mcp.CallToolRequest) (*m
 		Body:   strings.NewReader(pArgs.Body),
 	}
 
-	result, err := callDesc.Run(ctx, DefaultClient)
+	result, err := callDesc.Run(ctx, ClientFor(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("error executing call: %w", err)
 	}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var sessionClients = sync.Map{}
+
+// ClientFor returns the http client for the MCP session of the given context. Each session has its own
+// cookie jar, so that cookies will not be shared between different clients. Without session the
+// DefaultClient will be returned.
+func ClientFor(ctx context.Context) *http.Client {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil || session.SessionID() == "" {
+		return DefaultClient
+	}
+
+	if client, found := sessionClients.Load(session.SessionID()); found {
+		return client.(*http.Client)
+	}
+
+	client := &http.Client{}
+	client.Jar, _ = cookiejar.New(nil)
+
+	actual, _ := sessionClients.LoadOrStore(session.SessionID(), client)
+	return actual.(*http.Client)
+}
+
+// ReleaseSession removes the http client (and its cookies) of the given session.
+func ReleaseSession(sessionID string) {
+	sessionClients.Delete(sessionID)
+}
@@ -18,6 +18,11 @@ type EnvironmentResult struct {
 
 var EnvironmentTool = mcp.NewTool("getEnvironment",
 	mcp.WithDescription("Get all environment variables of the user's system."),
+	mcp.WithTitleAnnotation("Get environment variables"),
+	mcp.WithReadOnlyHintAnnotation(true),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 )
 
 var EnvironmentToolHandler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
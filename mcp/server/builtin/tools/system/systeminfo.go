@@ -27,6 +27,11 @@ type SystemInfoResult struct {
 
 var SystemInfoTool = mcp.NewTool("getSystemInformation",
 	mcp.WithDescription("Get the following information about the user's system: OS, architecture, number of CPUs, hostname, user directory, user ID, group ID, working directory, process ID."),
+	mcp.WithTitleAnnotation("Get system information"),
+	mcp.WithReadOnlyHintAnnotation(true),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(true),
+	mcp.WithOpenWorldHintAnnotation(false),
 )
 
 var SystemInfoToolHandler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
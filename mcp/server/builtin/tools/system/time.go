@@ -9,6 +9,11 @@ import (
 
 var SystemTimeTool = mcp.NewTool("getSystemTime",
 	mcp.WithDescription("Get the current system time."),
+	mcp.WithTitleAnnotation("Get system time"),
+	mcp.WithReadOnlyHintAnnotation(true),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithIdempotentHintAnnotation(false),
+	mcp.WithOpenWorldHintAnnotation(false),
 )
 var SystemTimeToolHandler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return mcp.NewToolResultText(time.Now().String()), nil
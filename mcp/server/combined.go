@@ -7,6 +7,7 @@ import (
 	"mcp-system-control/config/model"
 	"mcp-system-control/config/model/command"
 	bServer "mcp-system-control/mcp/server/builtin"
+	"mcp-system-control/mcp/server/builtin/tools/http"
 	cServer "mcp-system-control/mcp/server/custom"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -17,7 +18,8 @@ func NewServer(name, version string, bConfig model.BuiltIns, cConfig map[string]
 	s := server.NewMCPServer(
 		name,
 		version,
-		server.WithToolCapabilities(false),
+		server.WithToolCapabilities(true),
+		server.WithLogging(),
 		server.WithHooks(&server.Hooks{
 			OnBeforeAny: []server.BeforeAnyHookFunc{
 				func(ctx context.Context, id any, method mcp.MCPMethod, message any) {
@@ -37,8 +39,15 @@ func NewServer(name, version string, bConfig model.BuiltIns, cConfig map[string]
 					)
 				},
 			},
+			OnUnregisterSession: []server.OnUnregisterSessionHookFunc{
+				func(ctx context.Context, session server.ClientSession) {
+					http.ReleaseSession(session.SessionID())
+				},
+			},
 		}),
 	)
+	s.EnableSampling()
+
 	bServer.AddTools(s, bConfig, approvalRequester)
 	cServer.AddTools(s, cConfig, approvalRequester)
 
@@ -0,0 +1,206 @@
+package custom
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mcp-system-control/approval"
+	"mcp-system-control/config/model"
+	"mcp-system-control/config/model/command"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// DirWatcher keeps the tools of the server in sync with the tool definition files of a directory.
+type DirWatcher struct {
+	s                 *server.MCPServer
+	dir               string
+	policy            model.ToolPolicy
+	approvalRequester approval.Requester
+
+	// the names of the tools which are not defined by the directory
+	reserved map[string]bool
+	// the state of each (loaded or broken) file
+	files map[string]fileState
+	// the tool name of each successfully loaded file
+	tools map[string]string
+}
+
+// NewDirWatcher creates a watcher for the given directory. All tools which are already registered
+// at the server can not be overwritten by a tool definition file. The policy will be applied to each tool.
+func NewDirWatcher(s *server.MCPServer, dir string, policy model.ToolPolicy, approvalRequester approval.Requester) *DirWatcher {
+	w := &DirWatcher{
+		s:                 s,
+		dir:               dir,
+		policy:            policy,
+		approvalRequester: approvalRequester,
+		reserved:          map[string]bool{},
+		files:             map[string]fileState{},
+		tools:             map[string]string{},
+	}
+	for name := range s.ListTools() {
+		w.reserved[name] = true
+	}
+	return w
+}
+
+// Load loads all tool definition files of the directory and adds the tools to the server.
+// In contrast to the later reloads, any invalid file will be reported as error.
+func (w *DirWatcher) Load() error {
+	states, err := w.scan()
+	if err != nil {
+		return err
+	}
+
+	var tools []server.ServerTool
+	for _, path := range sortedKeys(states) {
+		w.files[path] = states[path]
+
+		definition, err := w.load(path)
+		if err != nil {
+			return err
+		}
+		definition, allowed := w.policy.Apply(definition)
+		if !allowed {
+			slog.Info("Custom tool skipped: it is not read-only.", "tool", definition.Name, "file", path)
+			continue
+		}
+		w.tools[path] = definition.Name
+		tools = append(tools, serverTool(definition.Name, definition, w.approvalRequester))
+	}
+
+	if len(tools) > 0 {
+		w.s.AddTools(tools...)
+	}
+	return nil
+}
+
+// Watch checks the directory periodically for changes until the context is done.
+func (w *DirWatcher) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Reload()
+		}
+	}
+}
+
+// Reload applies all changes of the directory to the server. Added and changed files will be (re)loaded, the tools
+// of removed files will be deleted. If a file is invalid, the error will be logged and the previous version of the
+// tool (if any) will be kept.
+func (w *DirWatcher) Reload() {
+	states, err := w.scan()
+	if err != nil {
+		slog.Error("Unable to reload custom tool directory.", "dir", w.dir, "error", err)
+		return
+	}
+
+	var deleted []string
+	for _, path := range sortedKeys(w.files) {
+		if _, exists := states[path]; exists {
+			continue
+		}
+		delete(w.files, path)
+		if name, loaded := w.tools[path]; loaded {
+			delete(w.tools, path)
+			deleted = append(deleted, name)
+			slog.Info("Custom tool removed.", "tool", name, "file", path)
+		}
+	}
+
+	var changed []server.ServerTool
+	for _, path := range sortedKeys(states) {
+		if previous, known := w.files[path]; known && previous == states[path] {
+			continue
+		}
+		// remember the current state, so that a broken file will not be loaded again and again
+		w.files[path] = states[path]
+
+		definition, err := w.load(path)
+		if err != nil {
+			slog.Error("Unable to reload custom tool.", "file", path, "error", err)
+			continue
+		}
+		definition, allowed := w.policy.Apply(definition)
+		if !allowed {
+			if name, loaded := w.tools[path]; loaded {
+				delete(w.tools, path)
+				deleted = append(deleted, name)
+			}
+			slog.Info("Custom tool skipped: it is not read-only.", "tool", definition.Name, "file", path)
+			continue
+		}
+		w.tools[path] = definition.Name
+		changed = append(changed, serverTool(definition.Name, definition, w.approvalRequester))
+		slog.Info("Custom tool loaded.", "tool", definition.Name, "file", path)
+	}
+
+	if len(deleted) > 0 {
+		w.s.DeleteTools(deleted...)
+	}
+	if len(changed) > 0 {
+		w.s.AddTools(changed...)
+	}
+}
+
+func (w *DirWatcher) load(path string) (command.FunctionDefinition, error) {
+	name := model.CustomToolName(path)
+	if w.reserved[name] {
+		return command.FunctionDefinition{}, fmt.Errorf("tool '%s' of file '%s' is already defined", name, path)
+	}
+	for other, otherName := range w.tools {
+		if other != path && otherName == name {
+			return command.FunctionDefinition{}, fmt.Errorf("tool '%s' of file '%s' is already defined by file '%s'", name, path, other)
+		}
+	}
+
+	return model.LoadCustomFile(path)
+}
+
+// scan returns the state of all tool definition files of the directory.
+func (w *DirWatcher) scan() (map[string]fileState, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read custom tool directory: %w", err)
+	}
+
+	result := map[string]fileState{}
+	for _, entry := range entries {
+		if entry.IsDir() || !model.IsCustomFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// the file was removed in the meantime
+			continue
+		}
+		result[filepath.Join(w.dir, entry.Name())] = fileState{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+	}
+	return result, nil
+}
+
+func sortedKeys(m map[string]fileState) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,160 @@
+package custom
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"mcp-system-control/config/model"
+	"mcp-system-control/config/model/command"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeToolFile(t *testing.T, dir, name, content string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func toolNames(s *server.MCPServer) []string {
+	var names []string
+	for name := range s.ListTools() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestDirWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeToolFile(t, dir, "hello.yaml", `
+description: Says hello.
+command: echo hello
+`)
+	writeToolFile(t, dir, "world.json", `{"description": "Says world.", "command": "echo world"}`)
+	writeToolFile(t, dir, "README.md", `not a tool`)
+
+	s := NewServer("test", map[string]command.FunctionDefinition{
+		"existing": {Description: "Existing tool."},
+	}, nil)
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, s.RegisterSession(t.Context(), session))
+
+	w := NewDirWatcher(s, dir, model.ToolPolicy{}, nil)
+	require.NoError(t, w.Load())
+	assert.Equal(t, []string{"existing", "hello", "world"}, toolNames(s))
+	assert.Equal(t, "Says hello.", s.GetTool("hello").Tool.Description)
+	assert.Equal(t, "object", s.GetTool("hello").Tool.InputSchema.Type)
+
+	// drain the notification of the initial load
+	require.Len(t, session.notifications, 1)
+	<-session.notifications
+
+	// nothing has changed
+	w.Reload()
+	assert.Len(t, session.notifications, 0)
+
+	writeToolFile(t, dir, "hello.yaml", `
+description: Says hello again.
+command: echo hello
+`)
+	require.NoError(t, os.Remove(filepath.Join(dir, "world.json")))
+	writeToolFile(t, dir, "new.yml", `
+description: New tool.
+command: echo new
+`)
+	writeToolFile(t, dir, "broken.yaml", `description: Without command.`)
+	writeToolFile(t, dir, "existing.yaml", `
+description: Overwrites the existing tool.
+command: echo existing
+`)
+
+	w.Reload()
+	assert.Equal(t, []string{"existing", "hello", "new"}, toolNames(s))
+	assert.Equal(t, "Says hello again.", s.GetTool("hello").Tool.Description)
+	assert.Equal(t, "Existing tool.", s.GetTool("existing").Tool.Description)
+
+	// one for the removed and one for the added/changed tools
+	require.Len(t, session.notifications, 2)
+	assert.Equal(t, mcp.MethodNotificationToolsListChanged, (<-session.notifications).Method)
+	assert.Equal(t, mcp.MethodNotificationToolsListChanged, (<-session.notifications).Method)
+
+	// a broken file keeps the previous version of the tool
+	writeToolFile(t, dir, "hello.yaml", `description: Without command.`)
+	w.Reload()
+	assert.Equal(t, "Says hello again.", s.GetTool("hello").Tool.Description)
+	assert.Len(t, session.notifications, 0)
+
+	// the tool of a removed broken file will be removed
+	require.NoError(t, os.Remove(filepath.Join(dir, "hello.yaml")))
+	w.Reload()
+	assert.Equal(t, []string{"existing", "new"}, toolNames(s))
+}
+
+func TestDirWatcher_CallTool(t *testing.T) {
+	dir := t.TempDir()
+	writeToolFile(t, dir, "greet.yaml", `
+description: Greets someone.
+parameters:
+  type: object
+  properties:
+    name:
+      type: string
+  required: [name]
+command: echo "hello $name"
+`)
+
+	s := NewServer("test", nil, nil)
+	require.NoError(t, NewDirWatcher(s, dir, model.ToolPolicy{}, nil).Load())
+
+	response := s.HandleMessage(t.Context(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "greet", "arguments": {"name": "world"}}}`))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+	assert.Equal(t, "hello world\n", response.(mcp.JSONRPCResponse).Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+}
+
+func TestDirWatcher_Load_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeToolFile(t, dir, "broken.yaml", `description: Without command.`)
+
+	s := NewServer("test", nil, nil)
+	assert.EqualError(t, NewDirWatcher(s, dir, model.ToolPolicy{}, nil).Load(), "Command for tool 'broken' is missing")
+
+	s = NewServer("test", nil, nil)
+	assert.ErrorContains(t, NewDirWatcher(s, filepath.Join(dir, "missing"), model.ToolPolicy{}, nil).Load(), "unable to read custom tool directory")
+}
+
+func TestDirWatcher_Load_Duplicate(t *testing.T) {
+	dir := t.TempDir()
+	writeToolFile(t, dir, "echo.json", `{"command": "echo"}`)
+	writeToolFile(t, dir, "echo.yaml", `command: echo`)
+
+	s := NewServer("test", nil, nil)
+	assert.ErrorContains(t, NewDirWatcher(s, dir, model.ToolPolicy{}, nil).Load(), "tool 'echo' of file '"+filepath.Join(dir, "echo.yaml")+"' is already defined by file")
+}
+
+func TestDirWatcher_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeToolFile(t, dir, "list.yaml", `
+command: ls
+annotations:
+  readOnlyHint: true
+`)
+	writeToolFile(t, dir, "remove.yaml", `command: rm -rf /tmp/something`)
+
+	s := NewServer("test", nil, nil)
+	w := NewDirWatcher(s, dir, model.ToolPolicy{ReadOnly: true, ReadOnlyApproval: "never"}, nil)
+	require.NoError(t, w.Load())
+	assert.Equal(t, []string{"list"}, toolNames(s))
+
+	// a tool which is not read-only anymore will be removed
+	writeToolFile(t, dir, "list.yaml", `
+command: ls -l
+annotations:
+  readOnlyHint: false
+`)
+	w.Reload()
+	assert.Empty(t, toolNames(s))
+}
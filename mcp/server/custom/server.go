@@ -3,6 +3,7 @@ package custom
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"mcp-system-control/approval"
 
 	"mcp-system-control/config/model/command"
@@ -15,20 +16,41 @@ func NewServer(version string, cfg map[string]command.FunctionDefinition, approv
 	s := server.NewMCPServer(
 		"mcp-system-control",
 		version,
-		server.WithToolCapabilities(false),
+		server.WithToolCapabilities(true),
+		server.WithLogging(),
 	)
+	s.EnableSampling()
+
 	AddTools(s, cfg, approvalRequester)
 
 	return s
 }
 
-func handlerFor(definition command.FunctionDefinition) server.ToolHandlerFunc {
+func handlerFor(definition command.FunctionDefinition, approvalRequester approval.Requester) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		raw, err := json.Marshal(request.Params.Arguments)
 		if err != nil {
 			return nil, err
 		}
 
+		if err = definition.ValidateArguments(string(raw)); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if definition.NeedApproval(ctx, string(raw)) {
+			approved, err := approvalRequester.WaitForApproval(ctx, &request)
+			if err != nil {
+				return nil, fmt.Errorf("error while waiting for approval: %w", err)
+			}
+			if !approved {
+				return nil, fmt.Errorf("tool call not approved")
+			}
+		}
+
+		if definition.ResultFn != nil {
+			return definition.ResultFn(ctx, string(raw))
+		}
+
 		rawResult, err := definition.CommandFn(ctx, string(raw))
 		return mcp.NewToolResultText(string(rawResult)), err
 	}
@@ -36,11 +58,18 @@ func handlerFor(definition command.FunctionDefinition) server.ToolHandlerFunc {
 
 func AddTools(s *server.MCPServer, cfg map[string]command.FunctionDefinition, approvalRequester approval.Requester) {
 	for name, definition := range cfg {
-		t := mcp.Tool{
+		s.AddTools(serverTool(name, definition, approvalRequester))
+	}
+}
+
+func serverTool(name string, definition command.FunctionDefinition, approvalRequester approval.Requester) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.Tool{
 			Name:        name,
 			Description: definition.Description,
 			InputSchema: definition.Parameters,
-		}
-		s.AddTool(t, handlerFor(definition))
+			Annotations: definition.Annotations,
+		},
+		Handler: handlerFor(definition, approvalRequester),
 	}
 }
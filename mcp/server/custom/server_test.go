@@ -2,13 +2,16 @@ package custom
 
 import (
 	"context"
+	"fmt"
 	"mcp-system-control/config/model/command"
+	"mcp-system-control/expression"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewServer(t *testing.T) {
@@ -72,3 +75,178 @@ func TestNewServer(t *testing.T) {
 	assert.NotNil(t, res)
 	assert.Equal(t, expectedResult, *res)
 }
+
+type testRequester struct {
+	approve bool
+	called  []string
+}
+
+func (t *testRequester) WaitForApproval(ctx context.Context, request *mcp.CallToolRequest) (bool, error) {
+	t.called = append(t.called, request.Params.Name)
+	return t.approve, nil
+}
+
+func TestAddTools_CallTool(t *testing.T) {
+	cfg := map[string]command.FunctionDefinition{
+		"inner": {
+			Approval: "true",
+			CommandFn: func(ctx context.Context, jsonArguments string) ([]byte, error) {
+				return []byte("inner: " + jsonArguments), nil
+			},
+		},
+		"outer": {
+			CommandFn: command.Expression(expression.FuncNameCallTool + `("inner", {"msg": "hello"}).content[0].text`).CommandFn(command.FunctionDefinition{}),
+		},
+	}
+
+	for _, approve := range []bool{true, false} {
+		t.Run(fmt.Sprintf("approve=%v", approve), func(t *testing.T) {
+			requester := &testRequester{approve: approve}
+			s := NewServer("test", cfg, requester)
+
+			c := client.NewClient(transport.NewInProcessTransport(s))
+			_, err := c.Initialize(t.Context(), mcp.InitializeRequest{})
+			require.NoError(t, err)
+
+			req := mcp.CallToolRequest{}
+			req.Params.Name = "outer"
+
+			res, err := c.CallTool(t.Context(), req)
+			assert.Equal(t, []string{"inner"}, requester.called)
+
+			if approve {
+				require.NoError(t, err)
+				assert.Equal(t, []mcp.Content{mcp.NewTextContent(`inner: {"msg":"hello"}`)}, res.Content)
+			} else {
+				assert.ErrorContains(t, err, "tool call not approved")
+			}
+		})
+	}
+}
+
+func TestAddTools_InvalidArguments(t *testing.T) {
+	definition := command.FunctionDefinition{
+		Approval: "true",
+		Parameters: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]any{"path": map[string]any{"type": "string"}},
+			Required:   []string{"path"},
+		},
+		CommandFn: func(ctx context.Context, jsonArguments string) ([]byte, error) {
+			t.Fatal("command should not be executed")
+			return nil, nil
+		},
+	}
+	require.NoError(t, definition.CompileSchema())
+
+	requester := &testRequester{approve: true}
+	s := NewServer("test", map[string]command.FunctionDefinition{"mkdir": definition}, requester)
+
+	c := client.NewClient(transport.NewInProcessTransport(s))
+	_, err := c.Initialize(t.Context(), mcp.InitializeRequest{})
+	require.NoError(t, err)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "mkdir"
+	req.Params.Arguments = map[string]any{"path": 42}
+
+	res, err := c.CallTool(t.Context(), req)
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("invalid arguments:\n- at '/path': got number, want string")}, res.Content)
+	assert.Empty(t, requester.called, "approval should not be requested")
+}
+
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (t *testSession) SessionID() string                                   { return "test" }
+func (t *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return t.notifications }
+func (t *testSession) Initialize()                                         {}
+func (t *testSession) Initialized() bool                                   { return true }
+func (t *testSession) SetLogLevel(level mcp.LoggingLevel)                  {}
+func (t *testSession) GetLogLevel() mcp.LoggingLevel                       { return mcp.LoggingLevelDebug }
+
+func TestAddTools_LogNotification(t *testing.T) {
+	s := NewServer("test", map[string]command.FunctionDefinition{
+		"logging": {
+			ResultFn: command.Expression(`log("hello"); console.error("world"); "OK"`).ResultFn(command.FunctionDefinition{}),
+		},
+	}, nil)
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	ctx := s.WithContext(t.Context(), session)
+
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "logging"}}`))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+	require.Len(t, session.notifications, 2)
+	n := <-session.notifications
+	assert.Equal(t, "notifications/message", n.Method)
+	assert.Equal(t, map[string]any{"level": mcp.LoggingLevelInfo, "logger": expression.LoggerName, "data": "hello"}, n.Params.AdditionalFields)
+
+	n = <-session.notifications
+	assert.Equal(t, map[string]any{"level": mcp.LoggingLevelError, "logger": expression.LoggerName, "data": "world"}, n.Params.AdditionalFields)
+}
+
+type testSamplingSession struct {
+	testSession
+	capabilities mcp.ClientCapabilities
+	requests     []mcp.CreateMessageRequest
+}
+
+func (t *testSamplingSession) GetClientInfo() mcp.Implementation              { return mcp.Implementation{} }
+func (t *testSamplingSession) SetClientInfo(mcp.Implementation)               {}
+func (t *testSamplingSession) GetClientCapabilities() mcp.ClientCapabilities  { return t.capabilities }
+func (t *testSamplingSession) SetClientCapabilities(c mcp.ClientCapabilities) { t.capabilities = c }
+func (t *testSamplingSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	t.requests = append(t.requests, request)
+
+	result := &mcp.CreateMessageResult{Model: "test-model"}
+	result.Role = mcp.RoleAssistant
+	result.Content = mcp.NewTextContent("summary")
+	return result, nil
+}
+
+func TestAddTools_Sample(t *testing.T) {
+	s := NewServer("test", map[string]command.FunctionDefinition{
+		"summarize": {
+			ResultFn: command.Expression(`sample({messages: ["Summarize this", {role: "assistant", content: "OK"}], maxTokens: 100, systemPrompt: "Be brief."})`).ResultFn(command.FunctionDefinition{}),
+		},
+	}, nil)
+
+	session := &testSamplingSession{
+		testSession:  testSession{notifications: make(chan mcp.JSONRPCNotification, 10)},
+		capabilities: mcp.ClientCapabilities{Sampling: &struct{}{}},
+	}
+	ctx := s.WithContext(t.Context(), session)
+
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "summarize"}}`))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+	assert.Equal(t, "summary", response.(mcp.JSONRPCResponse).Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+
+	require.Len(t, session.requests, 1)
+	assert.Equal(t, 100, session.requests[0].MaxTokens)
+	assert.Equal(t, "Be brief.", session.requests[0].SystemPrompt)
+	assert.Equal(t, []mcp.SamplingMessage{
+		{Role: mcp.RoleUser, Content: mcp.NewTextContent("Summarize this")},
+		{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("OK")},
+	}, session.requests[0].Messages)
+}
+
+func TestAddTools_Sample_NotSupported(t *testing.T) {
+	s := NewServer("test", map[string]command.FunctionDefinition{
+		"summarize": {
+			ResultFn: command.Expression(`sample({messages: ["Summarize this"]})`).ResultFn(command.FunctionDefinition{}),
+		},
+	}, nil)
+
+	session := &testSamplingSession{testSession: testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}}
+	ctx := s.WithContext(t.Context(), session)
+
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "summarize"}}`))
+	require.IsType(t, mcp.JSONRPCError{}, response)
+	assert.Contains(t, response.(mcp.JSONRPCError).Error.Message, "sampling failed: the mcp client does not support sampling")
+	assert.Empty(t, session.requests)
+}
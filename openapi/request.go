@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mcp-system-control/mcp/server/builtin/tools/http"
+	"net/url"
+	"strings"
+)
+
+// Settings contains the settings which are applied to each call of an operation.
+type Settings struct {
+	BaseUrl string
+	// Header will be sent with each call (e.g. for authentication)
+	Header map[string]string
+	// Query will be sent with each call (e.g. for an api key)
+	Query     map[string]string
+	TimeoutMs int64
+}
+
+// Request creates the http call for the given arguments of the tool.
+func (o *Operation) Request(settings Settings, args map[string]any) (*http.CallDescriptor, error) {
+	if settings.BaseUrl == "" {
+		return nil, fmt.Errorf("no base url available")
+	}
+
+	call := &http.CallDescriptor{
+		Method:    o.Method,
+		Query:     map[string]any{},
+		Header:    map[string]string{},
+		TimeoutMs: settings.TimeoutMs,
+	}
+	for key, value := range settings.Query {
+		call.Query[key] = value
+	}
+	for key, value := range settings.Header {
+		call.Header[key] = value
+	}
+
+	p := o.Path
+	var cookies []string
+	for _, param := range o.Parameters {
+		value, exists := args[param.Property]
+		if !exists || value == nil {
+			if param.Required {
+				return nil, fmt.Errorf("missing required argument '%s'", param.Property)
+			}
+			continue
+		}
+
+		switch param.In {
+		case ParameterInPath:
+			s, err := toString(value)
+			if err != nil {
+				return nil, err
+			}
+			p = strings.ReplaceAll(p, "{"+param.Name+"}", url.PathEscape(s))
+		case ParameterInQuery:
+			if list, isList := value.([]any); isList {
+				values := make([]string, 0, len(list))
+				for _, e := range list {
+					s, err := toString(e)
+					if err != nil {
+						return nil, err
+					}
+					values = append(values, s)
+				}
+				call.Query[param.Name] = values
+			} else {
+				s, err := toString(value)
+				if err != nil {
+					return nil, err
+				}
+				call.Query[param.Name] = s
+			}
+		case ParameterInHeader:
+			s, err := toString(value)
+			if err != nil {
+				return nil, err
+			}
+			call.Header[param.Name] = s
+		case ParameterInCookie:
+			s, err := toString(value)
+			if err != nil {
+				return nil, err
+			}
+			cookies = append(cookies, param.Name+"="+url.QueryEscape(s))
+		}
+	}
+	if len(cookies) > 0 {
+		call.Header["Cookie"] = strings.Join(cookies, "; ")
+	}
+
+	call.Url = strings.TrimSuffix(settings.BaseUrl, "/") + p
+
+	if o.Body != nil {
+		value, exists := args[o.Body.Property]
+		if !exists || value == nil {
+			if o.Body.Required {
+				return nil, fmt.Errorf("missing required argument '%s'", o.Body.Property)
+			}
+		} else if err := o.setBody(call, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return call, nil
+}
+
+func (o *Operation) setBody(call *http.CallDescriptor, value any) error {
+	if isJson(o.Body.ContentType) {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("unable to serialize body: %w", err)
+		}
+		call.StringBody = string(raw)
+		call.Header["Content-Type"] = o.Body.ContentType
+		return nil
+	}
+
+	if isForm(o.Body.ContentType) {
+		form, isMap := value.(map[string]any)
+		if !isMap {
+			return fmt.Errorf("the body must be an object")
+		}
+		call.Form = map[string]any{}
+		for key, v := range form {
+			s, err := toString(v)
+			if err != nil {
+				return err
+			}
+			call.Form[key] = s
+		}
+		return nil
+	}
+
+	if isMultipartForm(o.Body.ContentType) {
+		// the content type (including the boundary) will be set by the call
+		form, isMap := value.(map[string]any)
+		if !isMap {
+			return fmt.Errorf("the body must be an object")
+		}
+		for _, key := range sortedKeys(form) {
+			values, isList := form[key].([]any)
+			if !isList {
+				values = []any{form[key]}
+			}
+			for _, v := range values {
+				s, err := toString(v)
+				if err != nil {
+					return err
+				}
+				call.Multipart = append(call.Multipart, http.MultipartField{Name: key, Value: s})
+			}
+		}
+		return nil
+	}
+
+	s, err := toString(value)
+	if err != nil {
+		return err
+	}
+	call.StringBody = s
+	call.Header["Content-Type"] = o.Body.ContentType
+	return nil
+}
+
+// toString converts the value into a string. Strings are used as they are, all other values as JSON.
+func toString(value any) (string, error) {
+	if s, isString := value.(string); isString {
+		return s, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
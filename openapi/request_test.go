@@ -0,0 +1,129 @@
+package openapi
+
+import (
+	"mcp-system-control/mcp/server/builtin/tools/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadOperation(t *testing.T, name string) Operation {
+	spec, err := Load(filepath.Join("testdata", "petstore.yaml"))
+	require.NoError(t, err)
+
+	ops, err := spec.Select([]string{name}, nil)
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+	return ops[0]
+}
+
+func TestOperation_Request(t *testing.T) {
+	op := loadOperation(t, "listPets")
+
+	call, err := op.Request(Settings{
+		BaseUrl:   "https://api.example.com/v1/",
+		Header:    map[string]string{"Authorization": "Bearer token"},
+		Query:     map[string]string{"api_key": "secret"},
+		TimeoutMs: 1000,
+	}, map[string]any{
+		"limit":        float64(10),
+		"tags":         []any{"cat", "dog"},
+		"X-Request-Id": "42",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "GET", call.Method)
+	assert.Equal(t, "https://api.example.com/v1/pets", call.Url)
+	assert.Equal(t, map[string]any{
+		"api_key": "secret",
+		"limit":   "10",
+		"tags":    []string{"cat", "dog"},
+	}, call.Query)
+	assert.Equal(t, map[string]string{
+		"Authorization": "Bearer token",
+		"X-Request-Id":  "42",
+	}, call.Header)
+	assert.Equal(t, int64(1000), call.TimeoutMs)
+	assert.Empty(t, call.StringBody)
+}
+
+func TestOperation_Request_Path(t *testing.T) {
+	op := loadOperation(t, "delete_pets_petId")
+
+	call, err := op.Request(Settings{BaseUrl: "http://localhost"}, map[string]any{
+		"petId":      "a/b c",
+		"body_query": true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "DELETE", call.Method)
+	assert.Equal(t, "http://localhost/pets/a%2Fb%20c", call.Url)
+	assert.Equal(t, map[string]any{"body": "true"}, call.Query)
+}
+
+func TestOperation_Request_Body(t *testing.T) {
+	op := loadOperation(t, "createPet")
+
+	call, err := op.Request(Settings{BaseUrl: "http://localhost"}, map[string]any{
+		"body": map[string]any{"name": "Tom"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "POST", call.Method)
+	assert.Equal(t, `{"name":"Tom"}`, call.StringBody)
+	assert.Equal(t, "application/json", call.Header["Content-Type"])
+}
+
+func TestOperation_Request_Form(t *testing.T) {
+	op := Operation{
+		Method: "POST",
+		Path:   "/login",
+		Body:   &Body{ContentType: "application/x-www-form-urlencoded", Property: BodyProperty},
+	}
+
+	call, err := op.Request(Settings{BaseUrl: "http://localhost"}, map[string]any{
+		"body": map[string]any{"user": "root", "remember": true},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"user": "root", "remember": "true"}, call.Form)
+	assert.Empty(t, call.StringBody)
+}
+
+func TestOperation_Request_Multipart(t *testing.T) {
+	op := Operation{
+		Method: "POST",
+		Path:   "/upload",
+		Body:   &Body{ContentType: "multipart/form-data", Property: BodyProperty},
+	}
+
+	call, err := op.Request(Settings{BaseUrl: "http://localhost"}, map[string]any{
+		"body": map[string]any{"name": "notes.txt", "content": "hello", "tags": []any{"a", "b"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []http.MultipartField{
+		{Name: "content", Value: "hello"},
+		{Name: "name", Value: "notes.txt"},
+		{Name: "tags", Value: "a"},
+		{Name: "tags", Value: "b"},
+	}, call.Multipart)
+	assert.Empty(t, call.StringBody)
+	// the boundary will be added by the call
+	assert.NotContains(t, call.Header, "Content-Type")
+
+	_, err = op.Request(Settings{BaseUrl: "http://localhost"}, map[string]any{"body": "content"})
+	assert.EqualError(t, err, "the body must be an object")
+}
+
+func TestOperation_Request_Invalid(t *testing.T) {
+	op := loadOperation(t, "createPet")
+
+	_, err := op.Request(Settings{BaseUrl: "http://localhost"}, map[string]any{})
+	assert.EqualError(t, err, "missing required argument 'body'")
+
+	_, err = op.Request(Settings{}, map[string]any{"body": map[string]any{}})
+	assert.EqualError(t, err, "no base url available")
+}
@@ -0,0 +1,158 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxRefDepth limits the depth of nested references (e.g. for recursive schemas).
+const maxRefDepth = 32
+
+// resolver resolves the (local) references of the specification.
+type resolver struct {
+	doc map[string]any
+}
+
+// resolveMap returns the object itself or (if it is a reference) the referenced object.
+func (r *resolver) resolveMap(v any) (map[string]any, error) {
+	m := asMap(v)
+	for i := 0; i < maxRefDepth; i++ {
+		ref, isRef := m["$ref"].(string)
+		if !isRef {
+			return m, nil
+		}
+
+		target, err := r.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		m = asMap(target)
+	}
+	return nil, fmt.Errorf("too many nested references")
+}
+
+// lookup returns the value of the given local reference (json pointer): #/components/schemas/Pet
+func (r *resolver) lookup(ref string) (any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported reference '%s': only local references are supported", ref)
+	}
+
+	var current any = r.doc
+	for _, token := range strings.Split(ref[2:], "/") {
+		token, err := url.PathUnescape(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference '%s': %w", ref, err)
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		m, isMap := current.(map[string]any)
+		if !isMap {
+			return nil, fmt.Errorf("reference '%s' not found", ref)
+		}
+		var exists bool
+		if current, exists = m[token]; !exists {
+			return nil, fmt.Errorf("reference '%s' not found", ref)
+		}
+	}
+	return current, nil
+}
+
+// schema converts the given OpenAPI schema into a self-contained JSON schema: all references will be
+// resolved and the OpenAPI 3.0 specific keywords (nullable, boolean exclusiveMinimum/-Maximum) will be converted.
+func (r *resolver) schema(v any) (map[string]any, error) {
+	if v == nil {
+		return map[string]any{}, nil
+	}
+	result, err := r.convert(v, nil)
+	if err != nil {
+		return nil, err
+	}
+	if m, isMap := result.(map[string]any); isMap {
+		return m, nil
+	}
+	return nil, fmt.Errorf("schema must be an object")
+}
+
+func (r *resolver) convert(v any, refs []string) (any, error) {
+	switch value := v.(type) {
+	case map[string]any:
+		if ref, isRef := value["$ref"].(string); isRef {
+			for _, seen := range refs {
+				if seen == ref {
+					// recursive schema: the nested occurrence accepts anything
+					return map[string]any{}, nil
+				}
+			}
+			if len(refs) >= maxRefDepth {
+				return nil, fmt.Errorf("too many nested references")
+			}
+
+			target, err := r.lookup(ref)
+			if err != nil {
+				return nil, err
+			}
+			return r.convert(target, append(refs, ref))
+		}
+
+		result := make(map[string]any, len(value))
+		for key, child := range value {
+			if key == "properties" || key == "patternProperties" || key == "$defs" || key == "definitions" {
+				// the keys of these objects are names and not keywords
+				props := map[string]any{}
+				for name, prop := range asMap(child) {
+					converted, err := r.convert(prop, refs)
+					if err != nil {
+						return nil, err
+					}
+					props[name] = converted
+				}
+				result[key] = props
+				continue
+			}
+
+			converted, err := r.convert(child, refs)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = converted
+		}
+		convertKeywords(result)
+		return result, nil
+
+	case []any:
+		result := make([]any, len(value))
+		for i, child := range value {
+			converted, err := r.convert(child, refs)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = converted
+		}
+		return result, nil
+	}
+
+	return v, nil
+}
+
+// convertKeywords converts the OpenAPI 3.0 specific keywords into their JSON schema equivalent.
+func convertKeywords(schema map[string]any) {
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		if t, isString := schema["type"].(string); isString {
+			schema["type"] = []any{t, "null"}
+		}
+	}
+	delete(schema, "nullable")
+
+	for keyword, limit := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		exclusive, isBool := schema[keyword].(bool)
+		if !isBool {
+			continue
+		}
+		delete(schema, keyword)
+		if value, exists := schema[limit]; exists && exclusive {
+			schema[keyword] = value
+			delete(schema, limit)
+		}
+	}
+}
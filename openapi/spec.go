@@ -0,0 +1,404 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	ParameterInPath   = "path"
+	ParameterInQuery  = "query"
+	ParameterInHeader = "header"
+	ParameterInCookie = "cookie"
+)
+
+// BodyProperty is the name of the input property which contains the request body.
+const BodyProperty = "body"
+
+// errUnsupportedBody is returned if no content type of the request body is supported.
+var errUnsupportedBody = errors.New("unsupported content type of request body")
+
+var methods = []string{
+	http.MethodGet,
+	http.MethodPut,
+	http.MethodPost,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodHead,
+	http.MethodPatch,
+	http.MethodTrace,
+}
+
+// invalidNameChars matches all characters which are not allowed in a tool name.
+var invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Spec contains the operations of an OpenAPI 3 specification.
+type Spec struct {
+	Title string
+	// Servers contains the urls of the servers (variables are replaced by their default values)
+	Servers    []string
+	Operations []Operation
+}
+
+// Operation is an operation (method + path) of the specification.
+type Operation struct {
+	// Name is the name of the tool: the operationId or (if missing) the method and the path
+	Name        string
+	OperationId string
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+
+	Parameters []Parameter
+	Body       *Body
+
+	// InputSchema is the JSON schema of the tool's input. It contains one property per parameter and
+	// the property "body" for the request body.
+	InputSchema map[string]any
+}
+
+// Parameter is a parameter of an operation.
+type Parameter struct {
+	Name string
+	In   string
+	// Property is the name of the corresponding property of the input schema
+	Property string
+	Required bool
+}
+
+// Body describes the request body of an operation.
+type Body struct {
+	ContentType string
+	// Property is the name of the corresponding property of the input schema
+	Property string
+	Required bool
+}
+
+// Load reads the given OpenAPI 3 specification file (JSON or YAML).
+func Load(file string) (*Spec, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read OpenAPI specification '%s': %w", file, err)
+	}
+
+	// JSON is a subset of YAML, so both can be parsed the same way
+	var doc map[string]any
+	if err = yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse OpenAPI specification '%s': %w", file, err)
+	}
+
+	spec, err := parse(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI specification '%s': %w", file, err)
+	}
+	return spec, nil
+}
+
+func parse(doc map[string]any) (*Spec, error) {
+	version, _ := doc["openapi"].(string)
+	if version == "" {
+		if swagger, isSwagger := doc["swagger"]; isSwagger {
+			version = fmt.Sprint(swagger)
+		}
+	}
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported version '%s': only OpenAPI 3 is supported", version)
+	}
+
+	r := &resolver{doc: doc}
+	spec := &Spec{}
+
+	if info, ok := doc["info"].(map[string]any); ok {
+		spec.Title, _ = info["title"].(string)
+	}
+	for _, server := range asList(doc["servers"]) {
+		if u := serverUrl(asMap(server)); u != "" {
+			spec.Servers = append(spec.Servers, u)
+		}
+	}
+
+	paths := asMap(doc["paths"])
+	pathNames := make([]string, 0, len(paths))
+	for p := range paths {
+		pathNames = append(pathNames, p)
+	}
+	sort.Strings(pathNames)
+
+	names := map[string]bool{}
+	for _, p := range pathNames {
+		item, err := r.resolveMap(paths[p])
+		if err != nil {
+			return nil, fmt.Errorf("invalid path '%s': %w", p, err)
+		}
+
+		for _, method := range methods {
+			raw, exists := item[strings.ToLower(method)]
+			if !exists {
+				continue
+			}
+
+			op, err := r.operation(method, p, asMap(raw), asList(item["parameters"]))
+			if errors.Is(err, errUnsupportedBody) {
+				slog.Warn("Skipping OpenAPI operation.", "method", method, "path", p, "reason", err)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid operation '%s %s': %w", method, p, err)
+			}
+			if names[op.Name] {
+				return nil, fmt.Errorf("operation '%s %s': duplicate tool name '%s'", method, p, op.Name)
+			}
+			names[op.Name] = true
+
+			spec.Operations = append(spec.Operations, op)
+		}
+	}
+
+	return spec, nil
+}
+
+// Select returns all operations which match one of the given patterns (operationId or tool name, see path.Match)
+// and have one of the given tags. Empty patterns or tags will match all operations.
+func (s *Spec) Select(patterns, tags []string) ([]Operation, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid operation pattern '%s': %w", pattern, err)
+		}
+	}
+
+	var result []Operation
+	for _, op := range s.Operations {
+		if op.matches(patterns) && op.hasTag(tags) {
+			result = append(result, op)
+		}
+	}
+	return result, nil
+}
+
+func (o *Operation) matches(patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		for _, name := range []string{o.OperationId, o.Name} {
+			if matched, _ := path.Match(pattern, name); matched && name != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (o *Operation) hasTag(tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		for _, opTag := range o.Tags {
+			if tag == opTag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *resolver) operation(method, p string, raw map[string]any, pathParameters []any) (Operation, error) {
+	op := Operation{
+		Method: method,
+		Path:   p,
+	}
+	op.OperationId, _ = raw["operationId"].(string)
+	op.Summary, _ = raw["summary"].(string)
+	op.Description, _ = raw["description"].(string)
+	op.Deprecated, _ = raw["deprecated"].(bool)
+	for _, tag := range asList(raw["tags"]) {
+		if s, ok := tag.(string); ok {
+			op.Tags = append(op.Tags, s)
+		}
+	}
+
+	op.Name = sanitizeName(op.OperationId)
+	if op.Name == "" {
+		op.Name = strings.ToLower(method) + "_" + sanitizeName(p)
+	}
+
+	properties := map[string]any{}
+	var required []string
+
+	// the parameters of the operation overwrite the parameters of the path
+	var parameters []map[string]any
+	index := map[string]int{}
+	for _, rawParam := range append(pathParameters, asList(raw["parameters"])...) {
+		param, err := r.resolveMap(rawParam)
+		if err != nil {
+			return op, err
+		}
+		key := fmt.Sprintf("%v:%v", param["in"], param["name"])
+		if i, exists := index[key]; exists {
+			parameters[i] = param
+		} else {
+			index[key] = len(parameters)
+			parameters = append(parameters, param)
+		}
+	}
+
+	for _, param := range parameters {
+		p := Parameter{}
+		p.Name, _ = param["name"].(string)
+		p.In, _ = param["in"].(string)
+		p.Required, _ = param["required"].(bool)
+		if p.Name == "" {
+			return op, fmt.Errorf("parameter without name")
+		}
+		switch p.In {
+		case ParameterInPath:
+			// path parameters are always required
+			p.Required = true
+		case ParameterInQuery, ParameterInHeader, ParameterInCookie:
+		default:
+			return op, fmt.Errorf("parameter '%s' has an invalid location '%s'", p.Name, p.In)
+		}
+
+		p.Property = p.Name
+		if _, taken := properties[p.Property]; taken || p.Property == BodyProperty {
+			p.Property = p.Name + "_" + p.In
+		}
+
+		schema, err := r.schema(param["schema"])
+		if err != nil {
+			return op, fmt.Errorf("invalid schema of parameter '%s': %w", p.Name, err)
+		}
+		if description, ok := param["description"].(string); ok && description != "" {
+			schema["description"] = description
+		}
+		properties[p.Property] = schema
+		if p.Required {
+			required = append(required, p.Property)
+		}
+		op.Parameters = append(op.Parameters, p)
+	}
+
+	if raw["requestBody"] != nil {
+		body, err := r.resolveMap(raw["requestBody"])
+		if err != nil {
+			return op, err
+		}
+
+		content := asMap(body["content"])
+		contentType, media := selectContent(content)
+		if contentType == "" && len(content) > 0 {
+			return op, fmt.Errorf("%w: %s", errUnsupportedBody, strings.Join(sortedKeys(content), ", "))
+		}
+		if contentType != "" {
+			op.Body = &Body{ContentType: contentType, Property: BodyProperty}
+			op.Body.Required, _ = body["required"].(bool)
+
+			schema, err := r.schema(media["schema"])
+			if err != nil {
+				return op, fmt.Errorf("invalid schema of request body: %w", err)
+			}
+			if description, ok := body["description"].(string); ok && description != "" {
+				schema["description"] = description
+			}
+			properties[BodyProperty] = schema
+			if op.Body.Required {
+				required = append(required, BodyProperty)
+			}
+		}
+	}
+
+	op.InputSchema = map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		op.InputSchema["required"] = required
+	}
+
+	return op, nil
+}
+
+func sanitizeName(name string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+}
+
+// selectContent chooses the content type of the request body. JSON will be preferred, then forms.
+// Multipart types (except multipart/form-data) are not supported.
+func selectContent(content map[string]any) (string, map[string]any) {
+	types := sortedKeys(content)
+
+	for _, accept := range []func(string) bool{isJson, isForm, isMultipartForm, isSupported} {
+		for _, contentType := range types {
+			if accept(contentType) {
+				return contentType, asMap(content[contentType])
+			}
+		}
+	}
+	return "", nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func mediaType(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+}
+
+func isJson(contentType string) bool {
+	mt := mediaType(contentType)
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+func isForm(contentType string) bool {
+	return mediaType(contentType) == "application/x-www-form-urlencoded"
+}
+
+func isMultipartForm(contentType string) bool {
+	return mediaType(contentType) == "multipart/form-data"
+}
+
+// isSupported checks if the body can be sent with the given content type. Other types than JSON and
+// forms will be sent as they are (e.g. text/plain).
+func isSupported(contentType string) bool {
+	return !strings.HasPrefix(mediaType(contentType), "multipart/") || isMultipartForm(contentType)
+}
+
+// serverUrl returns the url of the server. The variables will be replaced by their default values.
+func serverUrl(server map[string]any) string {
+	u, _ := server["url"].(string)
+	for name, variable := range asMap(server["variables"]) {
+		if def, ok := asMap(variable)["default"]; ok {
+			u = strings.ReplaceAll(u, "{"+name+"}", fmt.Sprint(def))
+		}
+	}
+	return u
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func asList(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
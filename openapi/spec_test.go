@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	spec, err := Load(filepath.Join("testdata", "petstore.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Petstore", spec.Title)
+	assert.Equal(t, []string{"https://api.example.com/v1"}, spec.Servers)
+
+	require.Len(t, spec.Operations, 4)
+	assert.Equal(t, "listPets", spec.Operations[0].Name)
+	assert.Equal(t, "createPet", spec.Operations[1].Name)
+	assert.Equal(t, "showPetById", spec.Operations[2].Name)
+	assert.Equal(t, "delete_pets_petId", spec.Operations[3].Name)
+}
+
+func TestLoad_Parameters(t *testing.T) {
+	spec, err := Load(filepath.Join("testdata", "petstore.yaml"))
+	require.NoError(t, err)
+
+	op := spec.Operations[0]
+	assert.Equal(t, []Parameter{
+		{Name: "limit", In: ParameterInQuery, Property: "limit"},
+		{Name: "tags", In: ParameterInQuery, Property: "tags"},
+		{Name: "X-Request-Id", In: ParameterInHeader, Property: "X-Request-Id"},
+	}, op.Parameters)
+	assert.Nil(t, op.Body)
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"limit": map[string]any{
+				"type":             "integer",
+				"exclusiveMinimum": uint64(0),
+				"description":      "How many items to return",
+			},
+			"tags": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+			"X-Request-Id": map[string]any{"type": "string"},
+		},
+	}, op.InputSchema)
+
+	// path level parameter + a parameter which collides with the body property
+	op = spec.Operations[3]
+	assert.Equal(t, []Parameter{
+		{Name: "petId", In: ParameterInPath, Property: "petId", Required: true},
+		{Name: "body", In: ParameterInQuery, Property: "body_query"},
+	}, op.Parameters)
+	assert.Equal(t, []string{"petId"}, op.InputSchema["required"])
+	assert.True(t, op.Deprecated)
+}
+
+func TestLoad_Body(t *testing.T) {
+	spec, err := Load(filepath.Join("testdata", "petstore.yaml"))
+	require.NoError(t, err)
+
+	op := spec.Operations[1]
+	assert.Equal(t, &Body{ContentType: "application/json", Property: BodyProperty, Required: true}, op.Body)
+	assert.Equal(t, []string{BodyProperty}, op.InputSchema["required"])
+	assert.Equal(t, map[string]any{
+		"type":     "object",
+		"required": []any{"name"},
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string"},
+			"tag":  map[string]any{"type": []any{"string", "null"}},
+			// recursive reference
+			"parent": map[string]any{},
+		},
+	}, op.InputSchema["properties"].(map[string]any)[BodyProperty])
+}
+
+func TestLoad_BodyContentType(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+openapi: "3.1.0"
+paths:
+  /upload:
+    post:
+      operationId: upload
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+          text/plain:
+            schema:
+              type: string
+  /mixed:
+    post:
+      operationId: mixed
+      requestBody:
+        content:
+          multipart/mixed:
+            schema:
+              type: object
+  /note:
+    post:
+      operationId: note
+      requestBody:
+        content:
+          text/plain:
+            schema:
+              type: string
+`), 0644))
+
+	spec, err := Load(file)
+	require.NoError(t, err)
+
+	// the operation with the unsupported content type will be skipped
+	require.Len(t, spec.Operations, 2)
+	assert.Equal(t, "note", spec.Operations[0].Name)
+	assert.Equal(t, "text/plain", spec.Operations[0].Body.ContentType)
+	assert.Equal(t, "upload", spec.Operations[1].Name)
+	assert.Equal(t, "multipart/form-data", spec.Operations[1].Body.ContentType)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "version",
+			content: `swagger: "2.0"`,
+			err:     "unsupported version '2.0'",
+		},
+		{
+			name: "reference",
+			content: `
+openapi: "3.1.0"
+paths:
+  /pets:
+    get:
+      parameters:
+        - $ref: "#/components/parameters/Missing"
+`,
+			err: "reference '#/components/parameters/Missing' not found",
+		},
+		{
+			name: "external reference",
+			content: `
+openapi: "3.1.0"
+paths:
+  /pets:
+    $ref: "other.yaml#/paths/pets"
+`,
+			err: "only local references are supported",
+		},
+		{
+			name: "duplicate",
+			content: `
+openapi: "3.1.0"
+paths:
+  /a:
+    get:
+      operationId: same
+  /b:
+    get:
+      operationId: same
+`,
+			err: "duplicate tool name 'same'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "spec.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0644))
+
+			_, err := Load(file)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestSpec_Select(t *testing.T) {
+	spec, err := Load(filepath.Join("testdata", "petstore.yaml"))
+	require.NoError(t, err)
+
+	names := func(ops []Operation) (result []string) {
+		for _, op := range ops {
+			result = append(result, op.Name)
+		}
+		return
+	}
+
+	ops, err := spec.Select(nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, ops, 4)
+
+	ops, err = spec.Select([]string{"*Pet*"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"listPets", "createPet", "showPetById"}, names(ops))
+
+	// the generated name can also be used
+	ops, err = spec.Select([]string{"delete_pets_*"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"delete_pets_petId"}, names(ops))
+
+	ops, err = spec.Select([]string{"list*", "show*"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"listPets", "showPetById"}, names(ops))
+
+	ops, err = spec.Select(nil, []string{"admin"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"delete_pets_petId"}, names(ops))
+
+	_, err = spec.Select([]string{"["}, nil)
+	assert.ErrorContains(t, err, "invalid operation pattern")
+}
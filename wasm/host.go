@@ -0,0 +1,262 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mcp-system-control/approval"
+	"mcp-system-control/expression"
+	"mcp-system-control/mcp/server/builtin/tools/command"
+	"mcp-system-control/mcp/server/builtin/tools/file"
+	"mcp-system-control/mcp/server/builtin/tools/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const (
+	// HostModule is the name of the module which contains the host functions.
+	HostModule = "mcp"
+
+	// HostFuncReadFile reads a file via the builtin readTextFile tool: read_file(reqPtr i32, reqLen i32) -> i64
+	HostFuncReadFile = "read_file"
+	// HostFuncRun executes a command: run(reqPtr i32, reqLen i32) -> i64
+	HostFuncRun = "run"
+	// HostFuncFetch executes a http call: fetch(reqPtr i32, reqLen i32) -> i64
+	HostFuncFetch = "fetch"
+	// HostFuncApprove asks the user for approval: approve(reqPtr i32, reqLen i32) -> i64
+	HostFuncApprove = "approve"
+	// HostFuncLog writes a message into the log: log(msgPtr i32, msgLen i32)
+	HostFuncLog = "log"
+)
+
+// Permissions controls which operations of the host functions need the user's approval.
+type Permissions struct {
+	// RunApproval is the approval expression which is evaluated before a command is executed.
+	RunApproval approval.Approval
+	// FetchApproval is the approval expression which is evaluated before a http call is executed.
+	FetchApproval approval.Approval
+}
+
+type callKey struct{}
+
+func withCall(ctx context.Context, tool *Tool) context.Context {
+	return context.WithValue(ctx, callKey{}, tool)
+}
+
+func callFrom(ctx context.Context) *Tool {
+	tool, _ := ctx.Value(callKey{}).(*Tool)
+	return tool
+}
+
+type hostFunc func(ctx context.Context, request []byte) (any, error)
+
+func instantiateHost(ctx context.Context, r wazero.Runtime) error {
+	builder := r.NewHostModuleBuilder(HostModule)
+
+	for name, fn := range map[string]hostFunc{
+		HostFuncReadFile: readFile,
+		HostFuncRun:      run,
+		HostFuncFetch:    fetch,
+		HostFuncApprove:  approve,
+	} {
+		builder.NewFunctionBuilder().
+			WithGoModuleFunction(hostFunction(fn),
+				[]api.ValueType{api.ValueTypeI32, api.ValueTypeI32},
+				[]api.ValueType{api.ValueTypeI64}).
+			Export(name)
+	}
+
+	builder.NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(writeLog),
+			[]api.ValueType{api.ValueTypeI32, api.ValueTypeI32},
+			[]api.ValueType{}).
+		Export(HostFuncLog)
+
+	_, err := builder.Instantiate(ctx)
+	return err
+}
+
+// hostFunction wraps the given function so that the JSON request will be read from and the JSON
+// response ({"result": ..., "error": "..."}) will be written to the guest's memory.
+func hostFunction(fn hostFunc) api.GoModuleFunc {
+	return func(ctx context.Context, mod api.Module, stack []uint64) {
+		ptr, size := uint32(stack[0]), uint32(stack[1])
+
+		resp := response{}
+		if request, ok := mod.Memory().Read(ptr, size); !ok {
+			resp.Error = "request is out of memory range"
+		} else if callFrom(ctx) == nil {
+			resp.Error = "host functions are only available during tool calls"
+		} else {
+			// the request must be copied because the guest memory can be changed while handling the request
+			result, err := fn(ctx, append([]byte(nil), request...))
+			if err != nil {
+				resp.Error = err.Error()
+			} else {
+				resp.Result = result
+			}
+		}
+
+		raw, err := json.Marshal(resp)
+		if err != nil {
+			raw, _ = json.Marshal(response{Error: fmt.Sprintf("unable to serialize response: %s", err)})
+		}
+
+		respPtr, err := writeGuest(ctx, mod, raw)
+		if err != nil {
+			// will be reported as error of the guest's function call
+			panic(err)
+		}
+		stack[0] = pack(respPtr, uint32(len(raw)))
+	}
+}
+
+func writeLog(ctx context.Context, mod api.Module, stack []uint64) {
+	msg, ok := mod.Memory().Read(uint32(stack[0]), uint32(stack[1]))
+	if !ok {
+		return
+	}
+
+	tool := ""
+	if t := callFrom(ctx); t != nil {
+		tool = t.Name
+	}
+	slog.Info("wasm plugin log", "tool", tool, "message", string(msg))
+}
+
+// readFile reads the file by calling the builtin readTextFile tool. So the configuration of that tool
+// (approval, disabled) applies to the plugins, too.
+func readFile(ctx context.Context, request []byte) (any, error) {
+	args := map[string]any{}
+	if err := json.Unmarshal(request, &args); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	r, err := expression.CallTool(ctx, file.FileReadingTool.Name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	text := textOf(r)
+	if r.IsError {
+		return nil, fmt.Errorf("%s", text)
+	}
+
+	result := file.FileReadingResult{}
+	if err = json.Unmarshal([]byte(text), &result); err != nil {
+		return nil, fmt.Errorf("unexpected result: %w", err)
+	}
+	return result, nil
+}
+
+func run(ctx context.Context, request []byte) (any, error) {
+	cmd := command.CommandDescriptor{}
+	if err := json.Unmarshal(request, &cmd); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	cmdLine := cmd.CommandLine
+	if cmdLine == "" {
+		cmdLine = strings.TrimSpace(cmd.Name + " " + strings.Join(cmd.Arguments, " "))
+	}
+	env := map[string]any{}
+	for k, v := range cmd.Environment {
+		env[k] = v
+	}
+	for k, v := range cmd.AdditionalEnvironment {
+		env[k] = v
+	}
+
+	err := checkApproval(ctx, callFrom(ctx).plugin.permissions.RunApproval, request,
+		command.CommandExecutionTool.Name, map[string]any{
+			"command":           cmdLine,
+			"working_directory": cmd.WorkingDirectory,
+			"environment":       env,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return expression.ExecCommand(ctx, cmd)
+}
+
+func fetch(ctx context.Context, request []byte) (any, error) {
+	call := http.CallDescriptor{}
+	if err := json.Unmarshal(request, &call); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	err := checkApproval(ctx, callFrom(ctx).plugin.permissions.FetchApproval, request,
+		http.CallTool.Name, map[string]any{
+			"method": call.Method,
+			"url":    call.Url,
+			"header": call.Header,
+			"body":   call.StringBody,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return expression.Fetch(ctx, call)
+}
+
+func approve(ctx context.Context, request []byte) (any, error) {
+	req := struct {
+		Message string `json:"message"`
+		Details any    `json:"details"`
+	}{}
+	if err := json.Unmarshal(request, &req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	details := ""
+	switch d := req.Details.(type) {
+	case nil:
+	case string:
+		details = d
+	default:
+		raw, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize details: %w", err)
+		}
+		details = string(raw)
+	}
+
+	return expression.RequestApproval(ctx, req.Message, details)
+}
+
+// checkApproval evaluates the approval expression and asks the user if necessary. The approval request
+// is shown like the one of the corresponding builtin tool.
+func checkApproval(ctx context.Context, a approval.Approval, request []byte, toolName string, args map[string]any) error {
+	tool := callFrom(ctx)
+	if !a.NeedsApproval(ctx, string(request), tool) {
+		return nil
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = toolName
+	req.Params.Arguments = args
+
+	approved, err := expression.WaitForApproval(ctx, &req)
+	if err != nil {
+		return fmt.Errorf("approval failed: %w", err)
+	}
+	if !approved {
+		return fmt.Errorf("the user has not approved the %s request", toolName)
+	}
+	return nil
+}
+
+func textOf(r *mcp.CallToolResult) string {
+	var sb strings.Builder
+	for _, c := range r.Content {
+		if tc, ok := mcp.AsTextContent(c); ok {
+			sb.WriteString(tc.Text)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,253 @@
+package wasm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	// ExportAlloc is the name of the guest function which allocates memory: alloc(size i32) -> ptr i32
+	ExportAlloc = "alloc"
+	// ExportTools is the name of the guest function which returns the tool definitions as JSON array: tools() -> i64
+	ExportTools = "tools"
+	// ExportCall is the name of the guest function which handles a tool call:
+	// call(namePtr i32, nameLen i32, argsPtr i32, argsLen i32) -> i64
+	ExportCall = "call"
+
+	// initFunction is the start function of wasi reactor modules
+	initFunction = "_initialize"
+)
+
+// Tool is a tool which is exported by a wasm plugin.
+type Tool struct {
+	plugin *Plugin
+
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema,omitempty"`
+	Annotations map[string]any `json:"annotations,omitempty"`
+
+	// Approval is either a boolean or an approval expression.
+	Approval any `json:"approval,omitempty"`
+}
+
+// Plugin is a compiled wasm module.
+type Plugin struct {
+	path        string
+	module      wazero.CompiledModule
+	permissions Permissions
+}
+
+type response struct {
+	Result any    `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+var (
+	runtime     wazero.Runtime
+	runtimeErr  error
+	runtimeOnce sync.Once
+)
+
+// getRuntime returns the runtime which is shared by all plugins. It contains the wasi and the host module.
+func getRuntime() (wazero.Runtime, error) {
+	runtimeOnce.Do(func() {
+		ctx := context.Background()
+
+		runtime = wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+		if _, runtimeErr = wasi_snapshot_preview1.Instantiate(ctx, runtime); runtimeErr != nil {
+			runtimeErr = fmt.Errorf("unable to instantiate wasi: %w", runtimeErr)
+			return
+		}
+		if runtimeErr = instantiateHost(ctx, runtime); runtimeErr != nil {
+			runtimeErr = fmt.Errorf("unable to instantiate host module: %w", runtimeErr)
+		}
+	})
+	return runtime, runtimeErr
+}
+
+// LoadDir loads all wasm plugin files (*.wasm) of the given directory.
+func LoadDir(dir string, permissions Permissions) ([]Tool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read wasm plugin directory: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var result []Tool
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".wasm") {
+			continue
+		}
+
+		tools, err := Load(filepath.Join(dir, entry.Name()), permissions)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tools...)
+	}
+
+	return result, nil
+}
+
+// Load compiles the given wasm plugin file and reads its tool definitions.
+func Load(path string, permissions Permissions) ([]Tool, error) {
+	r, err := getRuntime()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read wasm plugin '%s': %w", path, err)
+	}
+
+	ctx := context.Background()
+	compiled, err := r.CompileModule(ctx, content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compile wasm plugin '%s': %w", path, err)
+	}
+
+	for _, name := range []string{ExportAlloc, ExportTools, ExportCall} {
+		if _, found := compiled.ExportedFunctions()[name]; !found {
+			return nil, fmt.Errorf("invalid wasm plugin '%s': missing exported function '%s'", path, name)
+		}
+	}
+
+	plugin := &Plugin{
+		path:        path,
+		module:      compiled,
+		permissions: permissions,
+	}
+
+	raw, err := plugin.invoke(ctx, ExportTools)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load wasm plugin '%s': %w", path, err)
+	}
+
+	var tools []Tool
+	if err = json.Unmarshal(raw, &tools); err != nil {
+		return nil, fmt.Errorf("invalid tool definitions of wasm plugin '%s': %w", path, err)
+	}
+	for i := range tools {
+		if tools[i].Name == "" {
+			return nil, fmt.Errorf("invalid wasm plugin '%s': missing name of tool #%d", path, i)
+		}
+		tools[i].plugin = plugin
+	}
+
+	return tools, nil
+}
+
+// Call calls the tool with the given (json) arguments and returns the result of the plugin.
+func (t *Tool) Call(ctx context.Context, jsonArguments string) (any, error) {
+	ctx = withCall(ctx, t)
+
+	raw, err := t.plugin.invoke(ctx, ExportCall, []byte(t.Name), []byte(jsonArguments))
+	if err != nil {
+		return nil, fmt.Errorf("error calling wasm plugin: %w", err)
+	}
+
+	resp := response{}
+	if err = json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response of wasm plugin: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("wasm plugin returned an error: %s", resp.Error)
+	}
+	return resp.Result, nil
+}
+
+// invoke calls the given exported function in a fresh module instance. So the plugins can not share
+// any state between two calls, and they do not have to free any allocated memory. The given arguments
+// will be written into the guest's memory and passed as (ptr, len) pairs.
+func (p *Plugin) invoke(ctx context.Context, fnName string, args ...[]byte) ([]byte, error) {
+	r, err := getRuntime()
+	if err != nil {
+		return nil, err
+	}
+
+	logWriter := &logWriter{path: p.path}
+	mod, err := r.InstantiateModule(ctx, p.module, wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions(initFunction).
+		WithStdout(logWriter).
+		WithStderr(logWriter).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader))
+	if err != nil {
+		return nil, fmt.Errorf("unable to instantiate module: %w", err)
+	}
+	defer mod.Close(ctx)
+
+	params := make([]uint64, 0, len(args)*2)
+	for _, arg := range args {
+		ptr, err := writeGuest(ctx, mod, arg)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, uint64(ptr), uint64(len(arg)))
+	}
+
+	results, err := mod.ExportedFunction(fnName).Call(ctx, params...)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("function '%s' must return exactly one value", fnName)
+	}
+
+	ptr, size := unpack(results[0])
+	raw, ok := mod.Memory().Read(ptr, size)
+	if !ok {
+		return nil, fmt.Errorf("function '%s' returned an out of range memory area", fnName)
+	}
+
+	// the memory will be released with the module
+	return append([]byte(nil), raw...), nil
+}
+
+// writeGuest allocates memory inside the guest and writes the given data into it.
+func writeGuest(ctx context.Context, mod api.Module, data []byte) (uint32, error) {
+	results, err := mod.ExportedFunction(ExportAlloc).Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("unable to allocate guest memory: %w", err)
+	}
+	ptr := uint32(results[0])
+
+	if !mod.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("guest allocated an out of range memory area")
+	}
+	return ptr, nil
+}
+
+func pack(ptr, size uint32) uint64 {
+	return uint64(ptr)<<32 | uint64(size)
+}
+
+func unpack(v uint64) (uint32, uint32) {
+	return uint32(v >> 32), uint32(v)
+}
+
+// logWriter forwards the output (stdout/stderr) of the plugins to the log.
+type logWriter struct {
+	path string
+}
+
+func (l *logWriter) Write(p []byte) (int, error) {
+	slog.Debug("wasm plugin output", "plugin", l.path, "output", strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,221 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"mcp-system-control/expression"
+	"mcp-system-control/mcp/server/builtin/tools/command"
+	"mcp-system-control/mcp/server/builtin/tools/file"
+	"mcp-system-control/mcp/server/builtin/tools/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var guestPath string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "mcp-system-control-wasm-test")
+	if err != nil {
+		panic(err)
+	}
+	guestPath = filepath.Join(dir, "guest.wasm")
+
+	cmd := exec.Command("go", "build", "-buildmode=c-shared", "-o", guestPath, ".")
+	cmd.Dir = filepath.Join("testdata", "guest")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		guestPath = ""
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func loadGuest(t *testing.T, permissions Permissions) map[string]Tool {
+	if guestPath == "" {
+		t.Skip("unable to build the test plugin")
+	}
+
+	tools, err := Load(guestPath, permissions)
+	require.NoError(t, err)
+
+	result := map[string]Tool{}
+	for _, tool := range tools {
+		result[tool.Name] = tool
+	}
+	return result
+}
+
+func TestLoad(t *testing.T) {
+	tools := loadGuest(t, Permissions{})
+
+	require.Len(t, tools, 6)
+	assert.Equal(t, "Echoes the arguments", tools["echo"].Description)
+	assert.Equal(t, "object", tools["echo"].InputSchema["type"])
+	assert.Equal(t, true, tools["echo"].Annotations["readOnlyHint"])
+	assert.Equal(t, false, tools["echo"].Approval)
+	assert.Equal(t, "cel:args.path.startsWith('/etc')", tools["readFile"].Approval)
+}
+
+func TestTool_Call(t *testing.T) {
+	tools := loadGuest(t, Permissions{})
+	tool := tools["echo"]
+
+	result, err := tool.Call(context.Background(), `{"text": "Hello World"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World", result)
+}
+
+func TestTool_Call_Approve(t *testing.T) {
+	tools := loadGuest(t, Permissions{})
+	tool := tools["approve"]
+
+	origRequestApproval := expression.RequestApproval
+	defer func() { expression.RequestApproval = origRequestApproval }()
+
+	expression.RequestApproval = func(ctx context.Context, msg, details string) (bool, error) {
+		assert.Equal(t, "Delete everything?", msg)
+		assert.Equal(t, "{\n  \"path\": \"/\"\n}", details)
+		return true, nil
+	}
+
+	result, err := tool.Call(context.Background(), `{"message": "Delete everything?", "details": {"path": "/"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, true, result)
+}
+
+func TestTool_Call_Error(t *testing.T) {
+	tools := loadGuest(t, Permissions{})
+	tool := tools["fail"]
+
+	_, err := tool.Call(context.Background(), `{}`)
+	assert.EqualError(t, err, "wasm plugin returned an error: failed on purpose")
+}
+
+func TestTool_Call_Run(t *testing.T) {
+	tools := loadGuest(t, Permissions{RunApproval: "never"})
+	tool := tools["run"]
+
+	origExec := expression.ExecCommand
+	defer func() { expression.ExecCommand = origExec }()
+
+	expression.ExecCommand = func(ctx context.Context, cmd command.CommandDescriptor) (*command.ExecutionResult, error) {
+		assert.Equal(t, "echo", cmd.Name)
+		assert.Equal(t, []string{"Hello"}, cmd.Arguments)
+		return &command.ExecutionResult{ExitCode: 0, Stdout: "Hello\n"}, nil
+	}
+
+	result, err := tool.Call(context.Background(), `{"name": "echo", "arguments": ["Hello"]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello\n", result.(map[string]any)["stdout"])
+}
+
+func TestTool_Call_Run_NotApproved(t *testing.T) {
+	tools := loadGuest(t, Permissions{RunApproval: `cel:args.name == "rm"`})
+	tool := tools["run"]
+
+	origExec := expression.ExecCommand
+	defer func() { expression.ExecCommand = origExec }()
+	expression.ExecCommand = func(ctx context.Context, cmd command.CommandDescriptor) (*command.ExecutionResult, error) {
+		t.Fatal("command should not be executed")
+		return nil, nil
+	}
+
+	expression.SetApprovalRequester(testRequester{approved: false})
+	defer expression.SetApprovalRequester(nil)
+
+	_, err := tool.Call(context.Background(), `{"name": "rm", "arguments": ["-rf", "/"]}`)
+	assert.EqualError(t, err, "wasm plugin returned an error: the user has not approved the executeCommand request")
+}
+
+func TestTool_Call_Fetch(t *testing.T) {
+	tools := loadGuest(t, Permissions{FetchApproval: "always"})
+	tool := tools["fetch"]
+
+	origFetch := expression.Fetch
+	defer func() { expression.Fetch = origFetch }()
+	expression.Fetch = func(ctx context.Context, call http.CallDescriptor) (*http.CallResult, error) {
+		assert.Equal(t, "https://example.com", call.Url)
+		return &http.CallResult{StatusCode: 200, Body: "OK"}, nil
+	}
+
+	requester := testRequester{approved: true, requests: new([]*mcp.CallToolRequest)}
+	expression.SetApprovalRequester(requester)
+	defer expression.SetApprovalRequester(nil)
+
+	result, err := tool.Call(context.Background(), `{"method": "GET", "url": "https://example.com"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", result.(map[string]any)["body"])
+
+	require.Len(t, *requester.requests, 1)
+	assert.Equal(t, http.CallTool.Name, (*requester.requests)[0].Params.Name)
+}
+
+func TestTool_Call_ReadFile(t *testing.T) {
+	tools := loadGuest(t, Permissions{})
+	tool := tools["readFile"]
+
+	tmp, err := os.CreateTemp("", "mcp-system-control-wasm-test")
+	require.NoError(t, err)
+	defer os.Remove(tmp.Name())
+	tmp.WriteString("Hello World")
+	tmp.Close()
+
+	s := server.NewMCPServer("test", "0.0.0")
+	s.AddTool(file.FileReadingTool, file.FileReadingToolHandler)
+	s.AddTool(mcp.NewTool("plugin"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := tool.Call(ctx, `{"path": "`+tmp.Name()+`"}`)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(result.(map[string]any)["content"].(string)), nil
+	})
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "plugin"}}`))
+	raw, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"text":"Hello World"`)
+}
+
+func TestTool_Call_ReadFile_NoServer(t *testing.T) {
+	tools := loadGuest(t, Permissions{})
+	tool := tools["readFile"]
+
+	_, err := tool.Call(context.Background(), `{"path": "/etc/hostname"}`)
+	assert.EqualError(t, err, "wasm plugin returned an error: no mcp server available")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tmp, err := os.CreateTemp("", "mcp-system-control-wasm-test")
+	require.NoError(t, err)
+	defer os.Remove(tmp.Name())
+
+	// a valid but empty module
+	tmp.Write([]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00})
+	tmp.Close()
+
+	_, err = Load(tmp.Name(), Permissions{})
+	assert.EqualError(t, err, "invalid wasm plugin '"+tmp.Name()+"': missing exported function 'alloc'")
+}
+
+type testRequester struct {
+	approved bool
+	requests *[]*mcp.CallToolRequest
+}
+
+func (r testRequester) WaitForApproval(ctx context.Context, request *mcp.CallToolRequest) (bool, error) {
+	if r.requests != nil {
+		*r.requests = append(*r.requests, request)
+	}
+	return r.approved, nil
+}
@@ -0,0 +1,115 @@
+//go:build wasip1
+
+// This is a test plugin for the wasm plugin loader. Build it with:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o guest.wasm .
+package main
+
+import (
+	"encoding/json"
+	"unsafe"
+)
+
+// keep the allocated buffers reachable for the garbage collector
+var buffers = map[uint32][]byte{}
+
+//go:wasmimport mcp read_file
+func hostReadFile(ptr, size uint32) uint64
+
+//go:wasmimport mcp run
+func hostRun(ptr, size uint32) uint64
+
+//go:wasmimport mcp fetch
+func hostFetch(ptr, size uint32) uint64
+
+//go:wasmimport mcp approve
+func hostApprove(ptr, size uint32) uint64
+
+//go:wasmimport mcp log
+func hostLog(ptr, size uint32)
+
+//go:wasmexport alloc
+func alloc(size uint32) uint32 {
+	b := make([]byte, size+1)
+	ptr := uint32(uintptr(unsafe.Pointer(&b[0])))
+	buffers[ptr] = b
+	return ptr
+}
+
+func read(ptr, size uint32) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(uintptr(ptr))), size)
+}
+
+func write(data []byte) uint64 {
+	ptr := alloc(uint32(len(data)))
+	copy(read(ptr, uint32(len(data))), data)
+	return uint64(ptr)<<32 | uint64(len(data))
+}
+
+func callHost(fn func(uint32, uint32) uint64, request any) (any, string) {
+	raw, _ := json.Marshal(request)
+	packed := write(raw)
+
+	response := struct {
+		Result any    `json:"result"`
+		Error  string `json:"error"`
+	}{}
+	result := fn(uint32(packed>>32), uint32(packed))
+	json.Unmarshal(read(uint32(result>>32), uint32(result)), &response)
+	return response.Result, response.Error
+}
+
+//go:wasmexport tools
+func tools() uint64 {
+	raw, _ := json.Marshal([]map[string]any{
+		{
+			"name":        "echo",
+			"description": "Echoes the arguments",
+			"inputSchema": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"text": map[string]any{"type": "string"}},
+			},
+			"annotations": map[string]any{"readOnlyHint": true},
+			"approval":    false,
+		},
+		{"name": "readFile", "approval": "cel:args.path.startsWith('/etc')"},
+		{"name": "run"},
+		{"name": "fetch"},
+		{"name": "approve"},
+		{"name": "fail"},
+	})
+	return write(raw)
+}
+
+//go:wasmexport call
+func call(namePtr, nameLen, argsPtr, argsLen uint32) uint64 {
+	name := string(read(namePtr, nameLen))
+	args := map[string]any{}
+	json.Unmarshal(read(argsPtr, argsLen), &args)
+
+	var result any
+	var err string
+	switch name {
+	case "echo":
+		msg := []byte("echo called")
+		hostLog(uint32(write(msg)>>32), uint32(len(msg)))
+		result = args["text"]
+	case "readFile":
+		result, err = callHost(hostReadFile, args)
+	case "run":
+		result, err = callHost(hostRun, args)
+	case "fetch":
+		result, err = callHost(hostFetch, args)
+	case "approve":
+		result, err = callHost(hostApprove, args)
+	case "fail":
+		err = "failed on purpose"
+	default:
+		err = "unknown tool: " + name
+	}
+
+	raw, _ := json.Marshal(map[string]any{"result": result, "error": err})
+	return write(raw)
+}
+
+func main() {}